@@ -0,0 +1,14 @@
+package main
+
+import (
+	"terraqt.io/colas/bedrock-go/pkg/config"
+	// 引入各包以注册其配置 section
+	_ "terraqt.io/colas/bedrock-go/pkg/db"
+	_ "terraqt.io/colas/bedrock-go/pkg/flags"
+	_ "terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+// runConfig 检查本模块各包注册的 section，服务可调用 config.RunCLI 检查自己的 section
+func runConfig(args []string) int {
+	return config.RunCLI("bedrock config", args)
+}
@@ -0,0 +1,32 @@
+// bedrock 是 bedrock-go 的命令行工具
+//
+//	bedrock config <validate|print|env|schema|keygen|encrypt|rotate> [flags]
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: bedrock <command> [arguments]
+
+commands:
+  config    validate, print and inspect configuration
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "config":
+		os.Exit(runConfig(os.Args[2:]))
+	case "-h", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
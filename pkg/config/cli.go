@@ -0,0 +1,331 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+const cliUsage = `usage: %s <subcommand> [flags]
+
+subcommands:
+  validate  load every section present in the config sources or env and report violations, exit 1 on failure
+  print     print the effective config of the present sections with sources, secrets masked
+  env       list every env var the loader honours
+  schema    print the JSON Schema of the config files
+  keygen    generate a random encryption key
+  encrypt   encrypt the given values (or stdin lines) into ENC[...] form
+  rotate    re-encrypt every ENC[...] value of the given files with the new key
+
+flags:
+`
+
+// stringList 支持重复传入的 flag，如 --config-dir a --config-dir b
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(val string) error {
+	*s = append(*s, val)
+	return nil
+}
+
+type configFlags struct {
+	env        string
+	dirs       stringList
+	trees      stringList
+	names      stringList
+	configType string
+	envPrefix  string
+	sections   stringList
+	strict     bool
+	format     string
+	keyFile    string
+	oldKeyFile string
+}
+
+// RunCLI 运行配置命令行工具，返回进程退出码。name 为用法说明中显示的命令，如 "bedrock config"。
+// 工具处理当前进程中通过 Section 注册的配置段，服务可在引入自己的包后调用，以检查服务自身的配置：
+//
+//	func main() {
+//		os.Exit(config.RunCLI("myservice config", os.Args[1:]))
+//	}
+func RunCLI(name string, args []string) int {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), cliUsage, name)
+		fs.PrintDefaults()
+	}
+
+	var f configFlags
+	fs.StringVar(&f.env, "env", "", "value of ENV, selects the overlay config file")
+	fs.Var(&f.dirs, FlagConfigDir, "config search path, repeatable")
+	fs.Var(&f.trees, FlagConfigTree, "directory config source with one file per key, repeatable")
+	fs.Var(&f.names, "config-name", "config file name without extension, repeatable, defaults to base and ENV")
+	fs.StringVar(&f.configType, FlagConfigType, "", "config file format")
+	fs.StringVar(&f.envPrefix, FlagEnvPrefix, "", "env var prefix")
+	fs.Var(&f.sections, "section", "only handle the given section, repeatable")
+	fs.BoolVar(&f.strict, "strict", false, "reject unknown keys in config files")
+	fs.StringVar(&f.format, "format", "text", "output format of print and env: text or json")
+	fs.StringVar(&f.keyFile, "key-file", "", "encryption key file, defaults to $"+EncryptionKeyEnv+" or $"+EncryptionKeyFileEnv)
+	fs.StringVar(&f.oldKeyFile, "old-key-file", "", "key file to decrypt with when rotating, defaults to the current key")
+
+	if len(args) == 0 {
+		fs.Usage()
+		return 2
+	}
+	sub := args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if f.env != "" {
+		_ = os.Setenv(ENV, f.env)
+	}
+
+	w := os.Stdout
+	switch sub {
+	case "validate":
+		return validateConfig(w, f)
+	case "print":
+		return printConfig(w, f)
+	case "env":
+		return printEnv(w, f)
+	case "schema":
+		return printSchema(w)
+	case "keygen":
+		return generateKey(w)
+	case "encrypt":
+		return encryptValues(w, f, fs.Args())
+	case "rotate":
+		return rotateFiles(w, f, fs.Args())
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n\n", sub)
+		fs.Usage()
+		return 2
+	}
+}
+
+func (f configFlags) options() []Option {
+	var opts []Option
+	if len(f.dirs) > 0 {
+		opts = append(opts, WithConfigPaths(f.dirs...))
+	}
+	if len(f.trees) > 0 {
+		opts = append(opts, WithConfigTree(f.trees...))
+	}
+	if len(f.names) > 0 {
+		opts = append(opts, WithConfigNames(f.names...))
+	}
+	if f.configType != "" {
+		opts = append(opts, WithConfigType(f.configType))
+	}
+	if f.envPrefix != "" {
+		opts = append(opts, WithEnvPrefix(f.envPrefix))
+	}
+	if f.strict {
+		opts = append(opts, WithStrict())
+	}
+	return opts
+}
+
+// inspectors 返回要处理的 section：指定了 --section 时为指定的 section，
+// 否则 onlyPresent 时只返回配置文件、目录源、远程配置源或环境变量中出现的 section，
+// 避免未使用的 section（如服务没有 database 段）因 required 字段报错。
+// MapSection 的每个子 key 单独返回，--section databases 选中其下所有子 key，--section databases.main 只选中一个；
+// onlyPresent 为 false 时另外返回子 key 为 <key> 的模板，用于列出环境变量的形式
+func (f configFlags) inspectors(onlyPresent bool) ([]Inspector, error) {
+	var present map[string]struct{}
+	if len(f.sections) == 0 && onlyPresent {
+		var err error
+		if present, err = presentKeys(f.options()...); err != nil {
+			return nil, err
+		}
+	}
+
+	all, err := Inspectors(f.options()...)
+	if err != nil {
+		return nil, err
+	}
+	if !onlyPresent {
+		all = append(all, mapSectionTemplates(f.options()...)...)
+		slices.SortFunc(
+			all, func(a, b Inspector) int {
+				return strings.Compare(a.Key(), b.Key())
+			},
+		)
+	}
+
+	var result []Inspector
+	for _, in := range all {
+		if len(f.sections) > 0 {
+			if slices.ContainsFunc(
+				f.sections, func(s string) bool {
+					return in.Key() == s || strings.HasPrefix(in.Key(), s+".")
+				},
+			) {
+				result = append(result, in)
+			}
+			continue
+		}
+		if present != nil {
+			top, _, _ := strings.Cut(in.Key(), ".")
+			if _, ok := present[top]; !ok && !envSet(in) {
+				continue
+			}
+		}
+		result = append(result, in)
+	}
+	return result, nil
+}
+
+// presentKeys 返回配置文件、目录源与远程配置源中出现的顶层 key
+func presentKeys(opts ...Option) (map[string]struct{}, error) {
+	l, err := readSources("", opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{})
+	for _, k := range l.AllKeys() {
+		top, _, _ := strings.Cut(k, ".")
+		keys[top] = struct{}{}
+	}
+	return keys, nil
+}
+
+func envSet(in Inspector) bool {
+	return slices.ContainsFunc(
+		in.EnvBindings(), func(b EnvBinding) bool {
+			return b.Set
+		},
+	)
+}
+
+func validateConfig(w io.Writer, f configFlags) int {
+	inspectors, err := f.inspectors(true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	code := 0
+	report := func(name string, err error) {
+		if err == nil {
+			fmt.Fprintf(w, "ok    %s\n", name)
+			return
+		}
+
+		code = 1
+		fmt.Fprintf(w, "FAIL  %s\n", name)
+
+		var invalid ErrConfigInvalid
+		if errors.As(err, &invalid) && len(invalid.Violations()) > 0 {
+			for _, v := range invalid.Violations() {
+				fmt.Fprintf(w, "      - %s\n", v)
+			}
+			return
+		}
+		fmt.Fprintf(w, "      - %v\n", err)
+	}
+
+	// section loader 只检查自己的 section，顶层 key 的拼写错误需单独检查
+	if f.strict && len(f.sections) == 0 {
+		report("top-level keys", checkTopLevelKeys(f.options()...))
+	}
+	for _, in := range inspectors {
+		report(in.Key(), in.Load())
+	}
+	return code
+}
+
+func printConfig(w io.Writer, f configFlags) int {
+	inspectors, err := f.inspectors(true)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var entries []ConfigEntry
+	code := 0
+	for _, in := range inspectors {
+		if err := in.Load(); err != nil {
+			// 校验失败时仍输出已读取的配置，便于排查
+			fmt.Fprintf(os.Stderr, "%s: %v\n", in.Key(), err)
+			code = 1
+		}
+		entries = append(entries, in.Explain()...)
+	}
+
+	if f.format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return code
+	}
+
+	if err := Dump(w, entries); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return code
+}
+
+func printEnv(w io.Writer, f configFlags) int {
+	inspectors, err := f.inspectors(false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	var bindings []EnvBinding
+	for _, in := range inspectors {
+		bindings = append(bindings, in.EnvBindings()...)
+	}
+
+	if f.format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(bindings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		return 0
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENV VAR\tKEY\tSET")
+	for _, b := range bindings {
+		set := ""
+		if b.Set {
+			set = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", b.EnvVar, b.Key, set)
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func printSchema(w io.Writer) int {
+	b, err := MarshalSchema(FileSchema(nil))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	_, _ = w.Write(append(b, '\n'))
+	return 0
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// encryptionKeys 优先读取 --key-file，否则使用 CONFIG_ENCRYPTION_KEY / CONFIG_ENCRYPTION_KEY_FILE
+func encryptionKeys(file string) ([][]byte, error) {
+	if file != "" {
+		return ReadEncryptionKeyFile(file)
+	}
+	return LoadEncryptionKeys()
+}
+
+func generateKey(w io.Writer) int {
+	key, err := GenerateEncryptionKey()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Fprintln(w, key)
+	return 0
+}
+
+// encryptValues 加密参数中的明文，未传参数时逐行读取标准输入
+func encryptValues(w io.Writer, f configFlags, args []string) int {
+	keys, err := encryptionKeys(f.keyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if len(args) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			args = append(args, strings.TrimRight(scanner.Text(), "\r"))
+		}
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	for _, plain := range args {
+		// 密钥列表的第一个用于加密
+		enc, err := EncryptValue(keys[0], plain)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Fprintln(w, enc)
+	}
+	return 0
+}
+
+// rotateFiles 使用旧密钥解密文件中的所有 ENC[...] 值，再用新密钥重新加密并原地写回，
+// 仅替换加密值本身，文件的其余内容与格式保持不变
+func rotateFiles(w io.Writer, f configFlags, files []string) int {
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "rotate requires at least one config file")
+		return 2
+	}
+
+	newKeys, err := encryptionKeys(f.keyFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	oldKeys := newKeys
+	if f.oldKeyFile != "" {
+		if oldKeys, err = ReadEncryptionKeyFile(f.oldKeyFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	for _, file := range files {
+		n, err := rotateFile(file, oldKeys, newKeys[0])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			return 1
+		}
+		fmt.Fprintf(w, "%s: %d value(s) rotated\n", file, n)
+	}
+	return 0
+}
+
+func rotateFile(file string, oldKeys [][]byte, newKey []byte) (int, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return 0, err
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return 0, err
+	}
+
+	rotated, n, err := RotateValues(string(content), oldKeys, newKey)
+	if err != nil {
+		// 任一值失败时不写回，避免文件中混用新旧密钥
+		return 0, err
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	return n, os.WriteFile(file, []byte(rotated), info.Mode().Perm())
+}
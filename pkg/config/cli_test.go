@@ -0,0 +1,101 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type cliTestDB struct {
+	Name string `mapstructure:"name" validate:"required"`
+	Host string `mapstructure:"host" validate:"required"`
+	Port int    `mapstructure:"port" validate:"lte=65535"`
+}
+
+func init() {
+	MapSection[cliTestDB](
+		"clitestdbs", func(name string) []Option {
+			return []Option{WithDefaults(map[string]any{"clitestdbs." + name + ".name": name})}
+		},
+	)
+}
+
+func TestValidateConfigMapSection(t *testing.T) {
+	t.Setenv("CLITESTDBS_ENVONLY_HOST", "h3")
+
+	dir := writeConfigFiles(
+		t, map[string]string{
+			"base.yaml": "clitestdbs:\n  main:\n    port: 99999\n  replica:\n    host: h2\n",
+		},
+	)
+	f := configFlags{dirs: stringList{dir}, names: stringList{"base"}}
+
+	var out bytes.Buffer
+	if code := validateConfig(&out, f); code != 1 {
+		t.Errorf("validateConfig() = %d, want 1\n%s", code, out.String())
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"FAIL  clitestdbs.main",
+		"field 'clitestdbs.main.host' valid failed: rule 'required'",
+		"field 'clitestdbs.main.port' valid failed: rule 'lte=65535'",
+		"ok    clitestdbs.envonly",
+		"ok    clitestdbs.replica",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("validateConfig() output missing %q:\n%s", want, got)
+		}
+	}
+
+	// --section 选中 map 下的单个子 key
+	out.Reset()
+	f.sections = stringList{"clitestdbs.replica"}
+	if code := validateConfig(&out, f); code != 0 || strings.TrimSpace(out.String()) != "ok    clitestdbs.replica" {
+		t.Errorf("validateConfig(--section clitestdbs.replica) = %d:\n%s", code, out.String())
+	}
+}
+
+func TestPrintEnvMapSection(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "clitestdbs:\n  main:\n    host: h\n"})
+	f := configFlags{dirs: stringList{dir}, names: stringList{"base"}, sections: stringList{"clitestdbs"}}
+
+	var out bytes.Buffer
+	if code := printEnv(&out, f); code != 0 {
+		t.Fatalf("printEnv() = %d", code)
+	}
+	for _, want := range []string{"CLITESTDBS_MAIN_HOST", "CLITESTDBS_<KEY>_HOST"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printEnv() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestValidateConfigStrictTopLevel(t *testing.T) {
+	dir := writeConfigFiles(
+		t, map[string]string{
+			"base.yaml": "include: []\ndatabse:\n  host: x\nclitestdbs:\n  main:\n    host: h\n    typo_key: 1\n",
+		},
+	)
+	f := configFlags{dirs: stringList{dir}, names: stringList{"base"}, strict: true}
+
+	var out bytes.Buffer
+	if code := validateConfig(&out, f); code != 1 {
+		t.Errorf("validateConfig() = %d, want 1\n%s", code, out.String())
+	}
+
+	got := out.String()
+	file := filepath.Join(dir, "base.yaml")
+	for _, want := range []string{
+		"FAIL  top-level keys\n      - field 'databse' valid failed: rule 'unknown_key', in '" + file + "'\n",
+		"FAIL  clitestdbs.main\n      - field 'clitestdbs.main.typo_key' valid failed: rule 'unknown_key', in '" + file + "'\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("validateConfig() output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "'include'") || strings.Contains(got, "'clitestdbs'") {
+		t.Errorf("validateConfig() reported a known top-level key:\n%s", got)
+	}
+}
@@ -5,15 +5,19 @@ import (
 )
 
 // Config 是应用程序的完整配置结构
+// 各包自己的配置（如 db、logger）通过 Section 注册，不再放在这里
 type Config struct {
 	//App       AppConfig       `mapstructure:"app" validate:"required"`
 	//Server    ServerConfig    `mapstructure:"server" validate:"required"`
-	Database  DatabaseConfig  `mapstructure:"database" validate:"omitempty"`
-	Logger    LoggerConfig    `mapstructure:"logger" validate:"omitempty"`
 	Cache     CacheConfig     `mapstructure:"cache" validate:"omitempty"`
 	Telemetry TelemetryConfig `mapstructure:"telemetry" validate:"omitempty"`
 }
 
+var (
+	CacheSection     = Section[CacheConfig]("cache")
+	TelemetrySection = Section[TelemetryConfig]("telemetry")
+)
+
 // AppConfig 包含应用基础配置
 type AppConfig struct {
 	Name        string `mapstructure:"name" validate:"required"`
@@ -24,32 +28,22 @@ type AppConfig struct {
 
 // ServerConfig HTTP服务器配置
 type ServerConfig struct {
-	Host            string        `mapstructure:"host" validate:"required"`
-	Port            int32         `mapstructure:"port" validate:"required,min=1,max=65535"`
-	ReadTimeout     time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout    time.Duration `mapstructure:"write_timeout"`
-	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
-	TrustedProxies  []string      `mapstructure:"trusted_proxies"`
-}
-
-// LoggerConfig 日志配置
-type LoggerConfig struct {
-	Level      string `mapstructure:"level" validate:"required,oneof=debug info warn error dpanic panic fatal"`
-	Format     string `mapstructure:"format" validate:"required,oneof=json console"`
-	AppName    string `mapstructure:"app_name"`
-	AppVersion string `mapstructure:"app_version"`
-
-	//OutputPath string `mapstructure:"output_path"`
+	Host            string        `mapstructure:"host" validate:"required" default:"0.0.0.0"`
+	Port            int32         `mapstructure:"port" validate:"required,min=1,max=65535" default:"8080"`
+	ReadTimeout     time.Duration `mapstructure:"read_timeout" default:"5s"`
+	WriteTimeout    time.Duration `mapstructure:"write_timeout" default:"10s"`
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" default:"30s"`
+	TrustedProxies  []string      `mapstructure:"trusted_proxies" default:"127.0.0.1,::1"`
 }
 
 // CacheConfig 缓存配置
 type CacheConfig struct {
 	Enabled    bool          `mapstructure:"enabled"`
-	Type       string        `mapstructure:"type" validate:"omitempty,oneof=memory redis"`
-	Addr       string        `mapstructure:"addr"`
-	Password   string        `mapstructure:"password"`
+	Type       string        `mapstructure:"type" validate:"omitempty,oneof=memory redis" default:"memory"`
+	Addr       string        `mapstructure:"addr" validate:"required_if=Enabled true Type redis"`
+	Password   string        `mapstructure:"password" secret:"true"`
 	DB         int           `mapstructure:"db"`
-	DefaultTTL time.Duration `mapstructure:"default_ttl"`
+	DefaultTTL time.Duration `mapstructure:"default_ttl" default:"5m"`
 }
 
 // TelemetryConfig 遥测配置
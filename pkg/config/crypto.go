@@ -0,0 +1,220 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// 加密值的格式为 ENC[aes-gcm,<base64(nonce|ciphertext)>]，密钥为 32 字节 AES-256 密钥的 base64
+const (
+	encAlgorithm = "aes-gcm"
+
+	// EncryptionKeyEnv 为密钥所在的环境变量，可包含多个密钥（逗号或换行分隔），第一个用于加密，其余用于轮换期间解密
+	EncryptionKeyEnv = "CONFIG_ENCRYPTION_KEY"
+	// EncryptionKeyFileEnv 为密钥文件路径所在的环境变量，文件格式同 EncryptionKeyEnv
+	EncryptionKeyFileEnv = "CONFIG_ENCRYPTION_KEY_FILE"
+)
+
+// EncryptedValuePattern 匹配整个配置值为加密值的情况，ENC[...] 只是值的一部分时不视为加密值
+var EncryptedValuePattern = regexp.MustCompile(`^ENC\[([a-z0-9-]+),([A-Za-z0-9+/=]+)\]$`)
+
+// encryptedValueSearch 在文件内容中查找加密值，用于密钥轮换
+var encryptedValueSearch = regexp.MustCompile(`ENC\[([a-z0-9-]+),([A-Za-z0-9+/=]+)\]`)
+
+var errNoEncryptionKey = errors.New("no encryption key, set " + EncryptionKeyEnv + " or " + EncryptionKeyFileEnv)
+
+// IsEncrypted 判断配置值是否为加密值
+func IsEncrypted(val string) bool {
+	return EncryptedValuePattern.MatchString(strings.TrimSpace(val))
+}
+
+// GenerateEncryptionKey 生成 base64 编码的随机密钥
+func GenerateEncryptionKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseEncryptionKeys 解析逗号或换行分隔的 base64 密钥列表
+func ParseEncryptionKeys(raw string) ([][]byte, error) {
+	var keys [][]byte
+	for _, item := range strings.FieldsFunc(
+		raw, func(r rune) bool {
+			return r == ',' || r == '\n' || r == '\r'
+		},
+	) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(item)
+		if err != nil {
+			return nil, fmt.Errorf("decode encryption key error: %w", err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errNoEncryptionKey
+	}
+	return keys, nil
+}
+
+// ReadEncryptionKeyFile 读取密钥文件
+func ReadEncryptionKeyFile(path string) ([][]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read encryption key file error: %w", err)
+	}
+	return ParseEncryptionKeys(string(content))
+}
+
+// LoadEncryptionKeys 依次从 EncryptionKeyEnv、EncryptionKeyFileEnv 读取密钥
+func LoadEncryptionKeys() ([][]byte, error) {
+	if raw, ok := os.LookupEnv(EncryptionKeyEnv); ok && raw != "" {
+		return ParseEncryptionKeys(raw)
+	}
+	if path, ok := os.LookupEnv(EncryptionKeyFileEnv); ok && path != "" {
+		return ReadEncryptionKeyFile(path)
+	}
+	return nil, errNoEncryptionKey
+}
+
+// EncryptValue 使用 key 加密明文，返回 ENC[...] 格式的值
+func EncryptValue(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return fmt.Sprintf("ENC[%s,%s]", encAlgorithm, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// DecryptValue 依次尝试 keys 解密 ENC[...] 格式的值
+func DecryptValue(keys [][]byte, val string) (string, error) {
+	match := EncryptedValuePattern.FindStringSubmatch(strings.TrimSpace(val))
+	if match == nil {
+		return "", errors.New("value is not encrypted")
+	}
+	if match[1] != encAlgorithm {
+		return "", fmt.Errorf("unsupported encryption algorithm '%s'", match[1])
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(match[2])
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted value error: %w", err)
+	}
+
+	for _, key := range keys {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return "", err
+		}
+		if len(sealed) < gcm.NonceSize() {
+			return "", errors.New("encrypted value too short")
+		}
+		plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+		if err == nil {
+			return string(plain), nil
+		}
+	}
+
+	return "", errors.New("decrypt failed with all encryption keys")
+}
+
+// RotateValues 使用 oldKeys 解密 text 中所有 ENC[...] 值并用 newKey 重新加密，返回新内容与轮换的个数。
+// 任一值失败时返回错误，此时不应写回，避免混用新旧密钥
+func RotateValues(text string, oldKeys [][]byte, newKey []byte) (string, int, error) {
+	var (
+		n       int
+		lastErr error
+	)
+	rotated := encryptedValueSearch.ReplaceAllStringFunc(
+		text, func(enc string) string {
+			if lastErr != nil {
+				return enc
+			}
+			plain, err := DecryptValue(oldKeys, enc)
+			if err != nil {
+				lastErr = err
+				return enc
+			}
+			re, err := EncryptValue(newKey, plain)
+			if err != nil {
+				lastErr = err
+				return enc
+			}
+			n++
+			return re
+		},
+	)
+	if lastErr != nil {
+		return text, 0, lastErr
+	}
+	return rotated, n, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptValues 解密所有 ENC[...] 值，解密后的 key 视为 secret，不会出现在日志与 Explain 中
+func (l *ViperLoader[T]) decryptValues() error {
+	var keys [][]byte
+
+	for _, key := range l.AllKeys() {
+		if !l.inSection(key) {
+			continue
+		}
+		val, ok := l.Get(key).(string)
+		if !ok || !IsEncrypted(val) {
+			continue
+		}
+
+		if keys == nil {
+			var err error
+			if keys, err = LoadEncryptionKeys(); err != nil {
+				return l.decryptError(key, err)
+			}
+		}
+
+		plain, err := DecryptValue(keys, val)
+		if err != nil {
+			return l.decryptError(key, err)
+		}
+		l.Set(key, plain)
+		l.secretKeys[key] = encAlgorithm
+	}
+
+	return nil
+}
+
+func (l *ViperLoader[T]) decryptError(key string, err error) error {
+	v := l.completeViolation(FieldViolation{Key: key, Rule: "decrypt", Param: err.Error()}, nil)
+	v.Value = secretMask
+	if source, origin := l.source(key, l.fileKeys()); source == SourceFile || source == SourceTree || source == SourceRemote {
+		v.Source = origin
+	}
+	return NewErrConfigInvalidWithViolations(fmt.Errorf("decrypt config '%s' error: %w", key, err), []FieldViolation{v})
+}
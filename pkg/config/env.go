@@ -0,0 +1,159 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+// decodeHook 在 viper 默认 hook 之前解析 JSON 字符串，
+// 使 slice、map、slice of struct 既可以用逗号分隔，也可以用 JSON 通过环境变量设置
+func decodeHook() viper.DecoderConfigOption {
+	return viper.DecodeHook(
+		mapstructure.ComposeDecodeHookFunc(
+			jsonStringHook,
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	)
+}
+
+func jsonStringHook(from reflect.Type, to reflect.Type, data any) (any, error) {
+	if from.Kind() != reflect.String {
+		return data, nil
+	}
+	switch to.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct:
+	default:
+		return data, nil
+	}
+
+	raw := strings.TrimSpace(data.(string))
+	if !strings.HasPrefix(raw, "[") && !strings.HasPrefix(raw, "{") {
+		return data, nil
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(raw), &decoded); err != nil {
+		return data, nil
+	}
+	return decoded, nil
+}
+
+// bindMapEnv 为 map 字段发现形如 PREFIX_SECTION_<KEY>_FIELD 的环境变量并逐个绑定。
+// map 的值为结构体时按其叶子字段匹配后缀，值为标量时 <KEY> 即为整个后缀
+//
+// 整个 map 的环境变量（JSON）已设置时只绑定它本身；未设置时不绑定，
+// 否则 viper 会用这个空的叶子 key 遮蔽 map 下的子 key
+func (l *ViperLoader[T]) bindMapEnv(key string, t reflect.Type) {
+	if _, ok := os.LookupEnv(l.envVarName(key)); ok || t.Key().Kind() != reflect.String {
+		_ = l.BindEnv(key)
+		return
+	}
+
+	for sub, name := range mapEnvVars(l.envVarName(key)+"_", t.Elem()) {
+		_ = l.BindEnv(key+"."+sub, name)
+	}
+}
+
+// mapEnvVars 发现以 prefix 开头、对应 map 子 key 的环境变量，elem 为 map 的值类型。
+// 返回相对于 map 的 key（值为结构体时为 <key>.<field>，否则为 <key>）到环境变量名的映射
+func mapEnvVars(prefix string, elem reflect.Type) map[string]string {
+	if elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	var fields []string
+	if elem.Kind() == reflect.Struct && elem != durationType {
+		walkLeafFields(
+			elem, "", func(sub string, _ reflect.StructField) {
+				fields = append(fields, sub)
+			},
+		)
+		// 优先匹配更长的后缀，避免 SSL_MODE 被 MODE 截断
+		sort.Slice(
+			fields, func(i, j int) bool {
+				return len(fields[i]) > len(fields[j])
+			},
+		)
+	}
+
+	result := make(map[string]string)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, prefix)
+
+		if fields == nil {
+			if rest != "" {
+				result[strings.ToLower(rest)] = name
+			}
+			continue
+		}
+
+		for _, sub := range fields {
+			suffix := "_" + envKeyReplacer.Replace(strings.ToUpper(sub))
+			if !strings.HasSuffix(rest, suffix) || len(rest) == len(suffix) {
+				continue
+			}
+			mapKey := strings.ToLower(strings.TrimSuffix(rest, suffix))
+			result[mapKey+"."+sub] = name
+			break
+		}
+	}
+	return result
+}
+
+// EnvBinding 描述 bind 会读取的一个环境变量
+type EnvBinding struct {
+	Key    string `json:"key"`
+	EnvVar string `json:"env_var"`
+	Set    bool   `json:"set"`
+}
+
+// EnvBindings 列出 bind 会读取的所有环境变量。
+// map 字段以 <KEY> 占位
+func (l *ViperLoader[T]) EnvBindings() []EnvBinding {
+	var zeroT T
+	var bindings []EnvBinding
+
+	add := func(key, name string) {
+		_, set := os.LookupEnv(name)
+		bindings = append(bindings, EnvBinding{Key: key, EnvVar: name, Set: set})
+	}
+
+	walkLeafFields(
+		reflect.TypeOf(zeroT), l.section, func(key string, f reflect.StructField) {
+			add(key, l.envVarName(key))
+			if f.Type.Kind() != reflect.Map {
+				return
+			}
+
+			elem := f.Type.Elem()
+			if elem.Kind() == reflect.Pointer {
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Struct || elem == durationType {
+				add(key+".<key>", l.envVarName(key)+"_<KEY>")
+				return
+			}
+			walkLeafFields(
+				elem, "", func(sub string, _ reflect.StructField) {
+					add(
+						key+".<key>."+sub,
+						l.envVarName(key)+"_<KEY>_"+envKeyReplacer.Replace(strings.ToUpper(sub)),
+					)
+				},
+			)
+		},
+	)
+
+	return bindings
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type envTestDB struct {
+	Host    string `mapstructure:"host"`
+	Mode    string `mapstructure:"mode"`
+	SSLMode string `mapstructure:"ssl_mode"`
+}
+
+func TestMapEnvVars(t *testing.T) {
+	t.Setenv("ENVTEST_DBS_MAIN_HOST", "h1")
+	t.Setenv("ENVTEST_DBS_MAIN_SSL_MODE", "require")
+	t.Setenv("ENVTEST_DBS_MY_DB_MODE", "ro")
+	t.Setenv("ENVTEST_DBS_HOST", "no map key")
+	t.Setenv("ENVTEST_DBS_MAIN_UNKNOWN", "ignored")
+
+	got := mapEnvVars("ENVTEST_DBS_", reflect.TypeOf(envTestDB{}))
+	want := map[string]string{
+		"main.host":     "ENVTEST_DBS_MAIN_HOST",
+		"main.ssl_mode": "ENVTEST_DBS_MAIN_SSL_MODE",
+		"my_db.mode":    "ENVTEST_DBS_MY_DB_MODE",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mapEnvVars() = %v, want %v", got, want)
+	}
+
+	// 值为指针时按其指向的结构体匹配
+	if got := mapEnvVars("ENVTEST_DBS_", reflect.TypeOf(&envTestDB{})); !reflect.DeepEqual(got, want) {
+		t.Errorf("mapEnvVars(pointer) = %v, want %v", got, want)
+	}
+}
+
+func TestMapEnvVarsScalar(t *testing.T) {
+	t.Setenv("ENVTEST_LIMITS_API", "10")
+	t.Setenv("ENVTEST_LIMITS_BULK_IMPORT", "2")
+	t.Setenv("ENVTEST_LIMITS_", "empty key")
+
+	got := mapEnvVars("ENVTEST_LIMITS_", reflect.TypeOf(0))
+	want := map[string]string{
+		"api":         "ENVTEST_LIMITS_API",
+		"bulk_import": "ENVTEST_LIMITS_BULK_IMPORT",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mapEnvVars() = %v, want %v", got, want)
+	}
+
+	// time.Duration 视为标量
+	if got := mapEnvVars("ENVTEST_LIMITS_", reflect.TypeOf(time.Duration(0))); !reflect.DeepEqual(got, want) {
+		t.Errorf("mapEnvVars(duration) = %v, want %v", got, want)
+	}
+}
+
+type envTestSection struct {
+	DBs    map[string]envTestDB `mapstructure:"dbs"`
+	Limits map[string]int       `mapstructure:"limits"`
+	Hosts  []string             `mapstructure:"hosts"`
+	Items  []envTestItem        `mapstructure:"items"`
+}
+
+type envTestItem struct {
+	Name string `mapstructure:"name"`
+}
+
+func TestLoadMapEnv(t *testing.T) {
+	t.Setenv("ENVTEST_DBS_MAIN_HOST", "h1")
+	t.Setenv("ENVTEST_DBS_MAIN_SSL_MODE", "require")
+	t.Setenv("ENVTEST_LIMITS_API", "10")
+	t.Setenv("ENVTEST_HOSTS", "a,b")
+	t.Setenv("ENVTEST_ITEMS", `[{"name":"x"},{"name":"y"}]`)
+
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "envtest:\n  dbs:\n    main:\n      mode: rw\n"})
+	cfg, err := Load[envTestSection](
+		NewSectionLoader[envTestSection]("envtest"), WithConfigPaths(dir), WithConfigNames("base"),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	wantDBs := map[string]envTestDB{"main": {Host: "h1", Mode: "rw", SSLMode: "require"}}
+	if !reflect.DeepEqual(cfg.DBs, wantDBs) {
+		t.Errorf("DBs = %+v, want %+v", cfg.DBs, wantDBs)
+	}
+	if !reflect.DeepEqual(cfg.Limits, map[string]int{"api": 10}) {
+		t.Errorf("Limits = %v", cfg.Limits)
+	}
+	if !reflect.DeepEqual(cfg.Hosts, []string{"a", "b"}) {
+		t.Errorf("Hosts = %v", cfg.Hosts)
+	}
+	if !reflect.DeepEqual(cfg.Items, []envTestItem{{Name: "x"}, {Name: "y"}}) {
+		t.Errorf("Items = %+v", cfg.Items)
+	}
+}
+
+func TestLoadMapEnvJSON(t *testing.T) {
+	// 整个 map 通过 JSON 设置时，忽略逐个 key 的环境变量
+	t.Setenv("ENVTEST_LIMITS", `{"bulk":3}`)
+	t.Setenv("ENVTEST_LIMITS_API", "10")
+
+	cfg, err := Load[envTestSection](
+		NewSectionLoader[envTestSection]("envtest"), WithConfigPaths(t.TempDir()), WithConfigNames("base"),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Limits, map[string]int{"bulk": 3}) {
+		t.Errorf("Limits = %v, want map[bulk:3]", cfg.Limits)
+	}
+}
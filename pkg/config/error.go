@@ -2,6 +2,8 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"terraqt.io/colas/bedrock-go/pkg/errs"
 )
 
@@ -35,14 +37,49 @@ func (e *errConfigNotFound) LackConfigName() string {
 	return e.lackConfigName
 }
 
+// FieldViolation 描述一条配置校验失败
+type FieldViolation struct {
+	// Key 为 mapstructure 点分路径，如 database.port
+	Key   string
+	Rule  string
+	Param string
+	// Value 为违规值，secret 字段会被打码
+	Value any
+	// EnvVar 为可覆盖该字段的环境变量名
+	EnvVar string
+	// Source 为违规值所在的配置文件，无法确定时为空
+	Source string
+}
+
+func (v FieldViolation) String() string {
+	rule := v.Rule
+	if v.Param != "" {
+		rule += "=" + v.Param
+	}
+	msg := fmt.Sprintf("field '%s' valid failed: rule '%s'", v.Key, rule)
+	// 未知 key 等违规没有值与环境变量，此时省略
+	if v.Value != nil && v.Value != "" {
+		msg += fmt.Sprintf(", value is '%v'", v.Value)
+	}
+	if v.EnvVar != "" {
+		msg += fmt.Sprintf(", env '%s'", v.EnvVar)
+	}
+	if v.Source != "" {
+		msg += fmt.Sprintf(", in '%s'", v.Source)
+	}
+	return msg
+}
+
 type ErrConfigInvalid interface {
 	errs.CodedError
 	InvalidConfig() string
+	Violations() []FieldViolation
 }
 
 type errConfigInvalid struct {
 	errs.CodedError
 	invalidConfig string
+	violations    []FieldViolation
 }
 
 func NewErrConfigInvalid(err error, invalidConfig string) ErrConfigInvalid {
@@ -60,6 +97,22 @@ func NewErrConfigInvalid(err error, invalidConfig string) ErrConfigInvalid {
 	}
 }
 
+// NewErrConfigInvalidWithViolations 创建携带结构化校验结果的 ErrConfigInvalid，InvalidConfig 由 violations 渲染
+func NewErrConfigInvalidWithViolations(err error, violations []FieldViolation) ErrConfigInvalid {
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		lines = append(lines, v.String())
+	}
+
+	e := NewErrConfigInvalid(err, strings.Join(lines, "\n - ")).(*errConfigInvalid)
+	e.violations = violations
+	return e
+}
+
 func (e *errConfigInvalid) InvalidConfig() string {
 	return e.invalidConfig
 }
+
+func (e *errConfigInvalid) Violations() []FieldViolation {
+	return e.violations
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestFieldViolationString(t *testing.T) {
+	tests := []struct {
+		v    FieldViolation
+		want string
+	}{
+		{
+			v:    FieldViolation{Key: "database.port", Rule: "lte", Param: "65535", Value: 99999, EnvVar: "DATABASE_PORT"},
+			want: "field 'database.port' valid failed: rule 'lte=65535', value is '99999', env 'DATABASE_PORT'",
+		},
+		{
+			v:    FieldViolation{Key: "database.host", Rule: "required", Value: "", EnvVar: "DATABASE_HOST"},
+			want: "field 'database.host' valid failed: rule 'required', env 'DATABASE_HOST'",
+		},
+		{
+			v:    FieldViolation{Key: "databse", Rule: "unknown_key", Source: "base.yaml"},
+			want: "field 'databse' valid failed: rule 'unknown_key', in 'base.yaml'",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
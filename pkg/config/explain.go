@@ -0,0 +1,253 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const secretMask = "******"
+
+// 配置值的来源
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceTree    = "tree"
+	SourceRemote  = "remote"
+	SourceDefault = "default"
+	SourceUnset   = "unset"
+)
+
+// ConfigEntry 描述一个叶子配置项的生效值及其来源
+type ConfigEntry struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+	// Origin 为来源的具体位置，如文件路径、环境变量名
+	Origin string `json:"origin,omitempty"`
+	// Resolver 为引用解析所用的 scheme（如 file、env）或解密算法（aes-gcm）
+	Resolver string `json:"resolver,omitempty"`
+	Secret   bool   `json:"secret"`
+}
+
+// Explainer 能够给出生效配置及其来源
+type Explainer interface {
+	Explain() []ConfigEntry
+}
+
+// Explain 返回所有叶子配置的生效值与来源，secret 字段会被打码
+func (l *ViperLoader[T]) Explain() []ConfigEntry {
+	l.loadMu.Lock()
+	defer l.loadMu.Unlock()
+
+	var zeroT T
+	keys := make(map[string]bool)
+	walkLeafFields(
+		reflect.TypeOf(zeroT), l.section, func(key string, f reflect.StructField) {
+			keys[key] = isSecretField(f)
+		},
+	)
+	for _, key := range l.AllKeys() {
+		if !l.inSection(key) {
+			continue
+		}
+		if _, ok := keys[key]; !ok {
+			keys[key] = false
+		}
+	}
+	// map 字段已展开为子 key 时不再单独列出，子 key 继承其 secret 标记
+	for key, secret := range keys {
+		expanded := false
+		for other := range keys {
+			if strings.HasPrefix(other, key+".") {
+				expanded = true
+				keys[other] = keys[other] || secret
+			}
+		}
+		if expanded {
+			delete(keys, key)
+		}
+	}
+
+	fileKeys := l.fileKeys()
+
+	entries := make([]ConfigEntry, 0, len(keys))
+	for key, secret := range keys {
+		entry := ConfigEntry{Key: key, Value: l.Get(key)}
+		entry.Source, entry.Origin = l.source(key, fileKeys)
+
+		if scheme, ok := l.secretKeys[key]; ok {
+			secret = true
+			entry.Resolver = scheme
+		}
+		if secret || isSecretKey(key) {
+			entry.Secret = true
+			if entry.Value != nil {
+				entry.Value = secretMask
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(
+		entries, func(a, b ConfigEntry) int {
+			return strings.Compare(a.Key, b.Key)
+		},
+	)
+
+	return entries
+}
+
+// source 按 viper 的优先级（env > 远程配置源 > 目录源 > 文件 > default）判断 key 的来源
+func (l *ViperLoader[T]) source(key string, fileKeys []map[string]struct{}) (string, string) {
+	name := l.envVarName(key)
+	if val, ok := os.LookupEnv(name); ok && val != "" {
+		return SourceEnv, name
+	}
+
+	for i := len(l.remoteKeys) - 1; i >= 0; i-- {
+		if _, ok := l.remoteKeys[i][key]; ok {
+			return SourceRemote, l.remotes[i].Name()
+		}
+	}
+
+	for i := len(l.treeKeys) - 1; i >= 0; i-- {
+		if file, ok := l.treeKeys[i][key]; ok {
+			return SourceTree, file
+		}
+	}
+
+	for i := len(fileKeys) - 1; i >= 0; i-- {
+		if _, ok := fileKeys[i][key]; ok {
+			return SourceFile, l.configFiles[i]
+		}
+	}
+
+	if _, ok := l.tagDefaults[key]; ok {
+		return SourceDefault, "tag"
+	}
+	if l.isDefault(key) {
+		return SourceDefault, ""
+	}
+
+	return SourceUnset, ""
+}
+
+// fileKeys 分别读取每个已加载的配置文件，用于判断 key 由哪个文件提供
+func (l *ViperLoader[T]) fileKeys() []map[string]struct{} {
+	result := make([]map[string]struct{}, len(l.configFiles))
+	for i, file := range l.configFiles {
+		result[i] = make(map[string]struct{})
+
+		settings, err := readConfigMap(file, l.configType)
+		if err != nil {
+			continue
+		}
+		delete(settings, includeKey)
+
+		v := viper.New()
+		_ = v.MergeConfigMap(settings)
+		for _, key := range v.AllKeys() {
+			result[i][key] = struct{}{}
+		}
+	}
+	return result
+}
+
+func (l *ViperLoader[T]) isDefault(key string) bool {
+	v := viper.New()
+	for k, val := range l.defaults {
+		v.SetDefault(k, val)
+	}
+	return v.IsSet(key)
+}
+
+// isSecretKey 对未打 tag 的动态 key 按常见命名兜底打码
+func isSecretKey(key string) bool {
+	last := key[strings.LastIndex(key, ".")+1:]
+	for _, word := range []string{"password", "secret", "token", "credential", "private_key"} {
+		if strings.Contains(last, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dump 以缩进树的形式输出生效配置，每个叶子后附带来源
+func Dump(w io.Writer, entries []ConfigEntry) error {
+	var printed []string
+	for _, entry := range entries {
+		parts := strings.Split(entry.Key, ".")
+
+		// 输出与上一个 key 不同的父级节点
+		common := 0
+		for common < len(parts)-1 && common < len(printed) && printed[common] == parts[common] {
+			common++
+		}
+		for i := common; i < len(parts)-1; i++ {
+			if _, err := fmt.Fprintf(w, "%s%s:\n", strings.Repeat("  ", i), parts[i]); err != nil {
+				return err
+			}
+		}
+		printed = parts[:len(parts)-1]
+
+		origin := entry.Source
+		if entry.Origin != "" {
+			origin += " " + entry.Origin
+		}
+		if entry.Resolver != "" {
+			origin += " via " + entry.Resolver
+		}
+		if _, err := fmt.Fprintf(
+			w, "%s%s: %v  # %s\n",
+			strings.Repeat("  ", len(parts)-1), parts[len(parts)-1], formatValue(entry.Value), origin,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatValue(val any) string {
+	if val == nil {
+		return "~"
+	}
+	switch v := val.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// ExplainHandler 返回输出生效配置及来源的 http.Handler，可挂载到管理端口。
+// 默认输出 JSON，?format=text 时输出 Dump 的树形文本
+func ExplainHandler(e Explainer) http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			entries := e.Explain()
+
+			if r.URL.Query().Get("format") == "text" {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				_ = Dump(w, entries)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			_ = json.NewEncoder(w).Encode(entries)
+		},
+	)
+}
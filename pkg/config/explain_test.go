@@ -0,0 +1,137 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type explainTestSection struct {
+	Password string            `mapstructure:"password" secret:"true"`
+	APIToken string            `mapstructure:"api_token"`
+	Labels   map[string]string `mapstructure:"labels" secret:"true"`
+	Extra    map[string]string `mapstructure:"extra"`
+	Level    string            `mapstructure:"level" default:"info"`
+	Unset    string            `mapstructure:"unset"`
+
+	Env     string `mapstructure:"env"`
+	Remote  string `mapstructure:"remote"`
+	Tree    string `mapstructure:"tree"`
+	File    string `mapstructure:"file"`
+	Base    string `mapstructure:"base"`
+	Default string `mapstructure:"default"`
+}
+
+func explainTest(t *testing.T) (map[string]ConfigEntry, string, string, string) {
+	t.Helper()
+
+	dir := writeConfigFiles(
+		t, map[string]string{
+			"base.yaml": "app:\n" +
+				"  password: p@ss\n" +
+				"  api_token: t0ken\n" +
+				"  labels:\n    team: core\n" +
+				"  extra:\n    region: eu\n    db_password: x\n" +
+				"  env: base\n  remote: base\n  tree: base\n  file: base\n  base: base\n",
+			"override.yaml": "app:\n  env: override\n  remote: override\n  tree: override\n  file: override\n",
+		},
+	)
+	treeDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(treeDir, "app.tree"), []byte("tree\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(treeDir, "app.remote"), []byte("tree\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_ENV", "env")
+
+	l := NewSectionLoader[explainTestSection]("app")
+	_, err := Load[explainTestSection](
+		l,
+		WithConfigPaths(dir),
+		WithConfigNames("base", "override"),
+		WithConfigTree(treeDir),
+		WithRemoteProvider(NewMemoryProvider("memory", map[string]any{"app.remote": "remote"})),
+		WithDefaults(map[string]any{"app.default": "d"}),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	entries := make(map[string]ConfigEntry)
+	for _, entry := range l.Explain() {
+		entries[entry.Key] = entry
+	}
+	return entries, filepath.Join(dir, "base.yaml"), filepath.Join(dir, "override.yaml"), treeDir
+}
+
+func TestExplainMasking(t *testing.T) {
+	entries, _, _, _ := explainTest(t)
+
+	tests := []struct {
+		key    string
+		secret bool
+	}{
+		// secret tag
+		{"app.password", true},
+		// 按 key 命名兜底
+		{"app.api_token", true},
+		{"app.extra.db_password", true},
+		// map 子 key 继承 map 字段的 secret tag
+		{"app.labels.team", true},
+		{"app.extra.region", false},
+		{"app.level", false},
+	}
+	for _, tt := range tests {
+		entry, ok := entries[tt.key]
+		if !ok {
+			t.Errorf("Explain() missing %s", tt.key)
+			continue
+		}
+		if entry.Secret != tt.secret {
+			t.Errorf("%s Secret = %v, want %v", tt.key, entry.Secret, tt.secret)
+		}
+		if tt.secret && entry.Value != secretMask {
+			t.Errorf("%s Value = %v, want masked", tt.key, entry.Value)
+		}
+		if !tt.secret && entry.Value == secretMask {
+			t.Errorf("%s Value is masked", tt.key)
+		}
+	}
+
+	// 展开为子 key 的 map 字段不再单独列出
+	for _, key := range []string{"app.labels", "app.extra"} {
+		if _, ok := entries[key]; ok {
+			t.Errorf("Explain() lists the expanded map %s", key)
+		}
+	}
+}
+
+func TestExplainSource(t *testing.T) {
+	entries, base, override, treeDir := explainTest(t)
+
+	tests := []struct {
+		key    string
+		source string
+		origin string
+	}{
+		{"app.env", SourceEnv, "APP_ENV"},
+		{"app.remote", SourceRemote, "memory"},
+		{"app.tree", SourceTree, filepath.Join(treeDir, "app.tree")},
+		{"app.file", SourceFile, override},
+		{"app.base", SourceFile, base},
+		{"app.level", SourceDefault, "tag"},
+		{"app.default", SourceDefault, ""},
+		{"app.unset", SourceUnset, ""},
+	}
+	for _, tt := range tests {
+		entry, ok := entries[tt.key]
+		if !ok {
+			t.Errorf("Explain() missing %s", tt.key)
+			continue
+		}
+		if entry.Source != tt.source || entry.Origin != tt.origin {
+			t.Errorf("%s source = %s %q, want %s %q", tt.key, entry.Source, entry.Origin, tt.source, tt.origin)
+		}
+	}
+}
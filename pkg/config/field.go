@@ -0,0 +1,234 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// walkLeafFields 按 mapstructure tag 遍历配置结构体，对每个叶子字段回调其点分 key
+func walkLeafFields(t reflect.Type, parent string, fn func(key string, f reflect.StructField)) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		// 跳过未导出字段 / 嵌入字段
+		if f.PkgPath != "" {
+			continue
+		}
+
+		tag := f.Tag.Get("mapstructure")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if parent != "" {
+			key = parent + "." + tag
+		}
+
+		if f.Type.Kind() == reflect.Struct &&
+			!(f.Type.PkgPath() == "time" && f.Type.Name() == "Duration") {
+			walkLeafFields(f.Type, key, fn)
+			continue
+		}
+
+		fn(key, f)
+	}
+}
+
+// isSecretField 判断字段是否标记了 `secret:"true"`
+func isSecretField(f reflect.StructField) bool {
+	return f.Tag.Get("secret") == "true"
+}
+
+// namespaceToKey 将 validator 的 Namespace（如 Config.database.port）转换为点分 key。
+// t 为根结构体类型，用于区分下标：map 的 key 转为点分段（items[foo].x -> items.foo.x），
+// 与配置文件、环境变量中的路径一致；slice 的下标保留，如 trusted_proxies[0]
+func namespaceToKey(t reflect.Type, namespace string) string {
+	_, rest, ok := strings.Cut(namespace, ".")
+	if !ok {
+		return namespace
+	}
+
+	var b strings.Builder
+	for i, segment := range strings.Split(rest, ".") {
+		name, indexes, _ := strings.Cut(segment, "[")
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(name)
+
+		t = fieldType(t, name)
+		if indexes == "" {
+			continue
+		}
+		for _, index := range strings.Split(strings.TrimSuffix(indexes, "]"), "][") {
+			if t != nil && t.Kind() == reflect.Map {
+				b.WriteString("." + index)
+			} else {
+				b.WriteString("[" + index + "]")
+			}
+			if t != nil {
+				t = derefType(t.Elem())
+			}
+		}
+	}
+	return b.String()
+}
+
+// fieldType 返回结构体 t 中 mapstructure 名称为 name 的字段类型，找不到时返回 nil
+func fieldType(t reflect.Type, name string) reflect.Type {
+	t = derefType(t)
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if f, ok := structField(t, name); ok {
+		return derefType(f.Type)
+	}
+	return nil
+}
+
+// secretPath 判断点分 key 对应的字段或其任一上级字段是否打了 secret tag，
+// map 类型的字段消耗一段作为 map 的 key
+func secretPath(t reflect.Type, key string) bool {
+	for _, segment := range strings.Split(key, ".") {
+		t = derefType(t)
+		switch {
+		case t == nil:
+			return false
+		case t.Kind() == reflect.Map:
+			t = t.Elem()
+		case t.Kind() == reflect.Struct:
+			f, ok := structField(t, segment)
+			if !ok {
+				return false
+			}
+			if isSecretField(f) {
+				return true
+			}
+			t = f.Type
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// structField 返回结构体 t 中 mapstructure 名称为 name 的字段
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if tag == name {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// stripIndex 去掉 key 中的下标，如 server.trusted_proxies[0] -> server.trusted_proxies
+func stripIndex(key string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range key {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseDefaultTag 将 `default:"..."` 按字段类型转换为默认值。
+// slice 使用逗号分隔，也支持以 [ 或 { 开头的 JSON（如 slice of struct、map）
+func parseDefaultTag(f reflect.StructField) (any, bool, error) {
+	tag, ok := f.Tag.Lookup("default")
+	if !ok {
+		return nil, false, nil
+	}
+
+	val, err := parseValue(f.Type, tag)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid default tag '%s': %w", tag, err)
+	}
+	return val, true, nil
+}
+
+func parseValue(t reflect.Type, raw string) (any, error) {
+	trimmed := strings.TrimSpace(raw)
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Struct) &&
+		(strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{")) {
+		ptr := reflect.New(t)
+		if err := json.Unmarshal([]byte(trimmed), ptr.Interface()); err != nil {
+			return nil, err
+		}
+		return ptr.Elem().Interface(), nil
+	}
+
+	if t == durationType {
+		return time.ParseDuration(trimmed)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(raw).Convert(t).Interface(), nil
+	case reflect.Bool:
+		return strconv.ParseBool(trimmed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(trimmed, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(v).Convert(t).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(trimmed, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(v).Convert(t).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(trimmed, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+		return reflect.ValueOf(v).Convert(t).Interface(), nil
+	case reflect.Slice:
+		slice := reflect.MakeSlice(t, 0, 0)
+		if trimmed == "" {
+			return slice.Interface(), nil
+		}
+		for _, item := range strings.Split(trimmed, ",") {
+			elem, err := parseValue(t.Elem(), strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			slice = reflect.Append(slice, reflect.ValueOf(elem).Convert(t.Elem()))
+		}
+		return slice.Interface(), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
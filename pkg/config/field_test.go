@@ -0,0 +1,111 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseValue(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	type level string
+
+	tests := []struct {
+		name string
+		t    reflect.Type
+		raw  string
+		want any
+	}{
+		{"string", reflect.TypeOf(""), " keep spaces ", " keep spaces "},
+		{"named string", reflect.TypeOf(level("")), "debug", level("debug")},
+		{"bool", reflect.TypeOf(false), "true", true},
+		{"int", reflect.TypeOf(0), " 42 ", 42},
+		{"int32", reflect.TypeOf(int32(0)), "-7", int32(-7)},
+		{"uint16", reflect.TypeOf(uint16(0)), "8080", uint16(8080)},
+		{"float", reflect.TypeOf(0.0), "0.5", 0.5},
+		{"duration", reflect.TypeOf(time.Duration(0)), "1m30s", 90 * time.Second},
+		{"slice", reflect.TypeOf([]string{}), "a, b,c", []string{"a", "b", "c"}},
+		{"empty slice", reflect.TypeOf([]int{}), "", []int{}},
+		{"int slice", reflect.TypeOf([]int{}), "1,2", []int{1, 2}},
+		{"json slice", reflect.TypeOf([]item{}), `[{"name":"a"}]`, []item{{Name: "a"}}},
+		{"json map", reflect.TypeOf(map[string]int{}), `{"a":1}`, map[string]int{"a": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := parseValue(tt.t, tt.raw)
+				if err != nil {
+					t.Fatalf("parseValue() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("parseValue() = %#v, want %#v", got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestParseValueError(t *testing.T) {
+	tests := []struct {
+		name string
+		t    reflect.Type
+		raw  string
+	}{
+		{"bool", reflect.TypeOf(false), "yes please"},
+		{"int overflow", reflect.TypeOf(int8(0)), "300"},
+		{"uint negative", reflect.TypeOf(uint(0)), "-1"},
+		{"duration", reflect.TypeOf(time.Duration(0)), "10"},
+		{"slice item", reflect.TypeOf([]int{}), "1,x"},
+		{"json", reflect.TypeOf([]int{}), "[1,"},
+		{"unsupported", reflect.TypeOf(map[string]int{}), "a=1"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got, err := parseValue(tt.t, tt.raw); err == nil {
+					t.Errorf("parseValue() = %#v, want error", got)
+				}
+			},
+		)
+	}
+}
+
+func TestNamespaceToKey(t *testing.T) {
+	type endpoint struct {
+		Port  int      `mapstructure:"port"`
+		Hosts []string `mapstructure:"hosts"`
+	}
+	type root struct {
+		Items          map[string]endpoint             `mapstructure:"items"`
+		Groups         map[string][]endpoint           `mapstructure:"groups"`
+		Nested         map[string]map[string]*endpoint `mapstructure:"nested"`
+		TrustedProxies []string                        `mapstructure:"trusted_proxies"`
+		Endpoints      []endpoint                      `mapstructure:"endpoints"`
+		Primary        *endpoint                       `mapstructure:"primary"`
+	}
+
+	tests := []struct {
+		namespace string
+		want      string
+	}{
+		{"root", "root"},
+		{"root.primary.port", "primary.port"},
+		// map 的 key 转为点分段
+		{"root.items[foo].port", "items.foo.port"},
+		{"root.items[foo].hosts[1]", "items.foo.hosts[1]"},
+		{"root.nested[a][b].port", "nested.a.b.port"},
+		{"root.groups[g][0].port", "groups.g[0].port"},
+		// slice 的下标保留
+		{"root.trusted_proxies[0]", "trusted_proxies[0]"},
+		{"root.endpoints[2].port", "endpoints[2].port"},
+		// 未知字段按 slice 处理
+		{"root.unknown[x].port", "unknown[x].port"},
+	}
+	for _, tt := range tests {
+		if got := namespaceToKey(reflect.TypeOf(root{}), tt.namespace); got != tt.want {
+			t.Errorf("namespaceToKey(%q) = %q, want %q", tt.namespace, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// includeKey 为配置文件中的 include 指令，值为单个路径或路径列表，相对路径基于当前文件所在目录。
+// 被 include 的文件先合并，当前文件中的值覆盖它们
+const includeKey = "include"
+
+var errConfigFileNotFound = errors.New("config file not found")
+
+// readConfigFiles 按 configNames 顺序查找并合并配置文件，不存在的文件跳过。
+// configFiles 按实际合并顺序记录所有文件（包括 include 的文件）
+func (l *ViperLoader[T]) readConfigFiles() error {
+	l.configFiles = l.configFiles[:0]
+
+	for _, name := range l.configNames {
+		file, err := l.findConfigFile(name)
+		if errors.Is(err, errConfigFileNotFound) {
+			continue
+		}
+		if err := l.mergeConfigFile(file, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findConfigFile 与 viper 的查找规则一致：依次在各搜索目录下尝试所有支持的扩展名
+func (l *ViperLoader[T]) findConfigFile(name string) (string, error) {
+	for _, dir := range l.configPaths {
+		for _, ext := range viper.SupportedExts {
+			file := filepath.Join(dir, name+"."+ext)
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				return file, nil
+			}
+		}
+	}
+	return "", errConfigFileNotFound
+}
+
+func (l *ViperLoader[T]) mergeConfigFile(file string, stack []string) error {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		abs = file
+	}
+
+	if slices.Contains(stack, abs) {
+		chain := strings.Join(append(stack, abs), " -> ")
+		return NewErrConfigInvalidWithViolations(
+			fmt.Errorf("config include cycle: %s", chain),
+			[]FieldViolation{
+				{Key: includeKey, Rule: "include_cycle", Value: file, Source: stack[len(stack)-1]},
+			},
+		)
+	}
+
+	settings, err := readConfigMap(abs, l.configType)
+	if err != nil {
+		if len(stack) > 0 {
+			return NewErrConfigNotFound(
+				fmt.Errorf("read config '%s' included by '%s' error: %w", abs, stack[len(stack)-1], err),
+				abs,
+			)
+		}
+		return NewErrConfigNotFound(fmt.Errorf("read config error: %w", err), abs)
+	}
+
+	includes, err := includePaths(settings[includeKey])
+	if err != nil {
+		return NewErrConfigInvalidWithViolations(
+			err,
+			[]FieldViolation{{Key: includeKey, Rule: "include", Value: settings[includeKey], Source: abs}},
+		)
+	}
+	delete(settings, includeKey)
+
+	for _, inc := range includes {
+		if !filepath.IsAbs(inc) {
+			inc = filepath.Join(filepath.Dir(abs), inc)
+		}
+		if err := l.mergeConfigFile(inc, append(stack, abs)); err != nil {
+			return err
+		}
+	}
+
+	if err := l.MergeConfigMap(settings); err != nil {
+		return NewErrConfigInvalid(fmt.Errorf("merge config '%s' error: %w", abs, err), abs)
+	}
+	l.configFiles = append(l.configFiles, abs)
+
+	return nil
+}
+
+// readConfigMap 读取单个配置文件，无扩展名时使用 configType 解析
+func readConfigMap(file string, configType string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if filepath.Ext(file) == "" {
+		v.SetConfigType(configType)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+func includePaths(val any) ([]string, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		paths := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("include item must be a string, got %T", item)
+			}
+			paths = append(paths, s)
+		}
+		return paths, nil
+	default:
+		return nil, fmt.Errorf("include must be a string or a list of strings, got %T", val)
+	}
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// placeholder 匹配 ${NAME}、${NAME:-default}，$${...} 为转义，输出字面量 ${...}
+var placeholder = regexp.MustCompile(`\$?\$\{([^}]*)\}`)
+
+// interpolate 展开配置值中的占位符：
+//   - ${VAR} / ${VAR:-default} 读取环境变量，未设置且无默认值时报错，变量为空时使用默认值
+//   - 名称中含 . 的视为配置自引用，如 ${database.host}，引用加密值或 secret 引用时取明文；
+//     引用了 secret（加密值、secret 引用、打了 secret tag 或按命名视为 secret 的 key）时，引用方同样视为 secret
+//
+// 需在 decryptValues、resolveSecrets 之后执行，已解密的明文不再展开
+func (l *ViperLoader[T]) interpolate() error {
+	// 按 key 排序，引用环路等错误总是从同一个 key 报出
+	keys := l.AllKeys()
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if !l.inSection(key) {
+			continue
+		}
+		if _, ok := l.secretKeys[key]; ok {
+			continue
+		}
+		val, ok := l.Get(key).(string)
+		if !ok || !strings.Contains(val, "${") {
+			continue
+		}
+
+		expanded, secret, scheme, err := l.expand(val, []string{key})
+		if err != nil {
+			return l.interpolateError(key, val, err)
+		}
+		l.Set(key, expanded)
+		if secret {
+			l.secretKeys[key] = scheme
+		}
+	}
+
+	return nil
+}
+
+// expand 返回展开后的值，引用了 secret 时 secret 为 true，scheme 为解析或解密所用的 scheme / 算法，
+// 引用的是明文的 secret 字段时 scheme 为空
+func (l *ViperLoader[T]) expand(val string, stack []string) (string, bool, string, error) {
+	var (
+		expandErr error
+		secret    bool
+		scheme    string
+	)
+
+	expanded := placeholder.ReplaceAllStringFunc(
+		val, func(match string) string {
+			if expandErr != nil {
+				return match
+			}
+			if strings.HasPrefix(match, "$$") {
+				return match[1:]
+			}
+
+			inner := match[2 : len(match)-1]
+			name, def, hasDef := strings.Cut(inner, ":-")
+			name = strings.TrimSpace(name)
+
+			var (
+				resolved string
+				err      error
+			)
+			if strings.Contains(name, ".") {
+				var (
+					refSecret bool
+					refScheme string
+				)
+				resolved, refSecret, refScheme, err = l.expandRef(strings.ToLower(name), stack)
+				secret = secret || refSecret
+				if refScheme != "" {
+					scheme = refScheme
+				}
+			} else {
+				resolved, err = expandEnv(name, def, hasDef)
+			}
+			if err != nil {
+				expandErr = err
+				return match
+			}
+			return resolved
+		},
+	)
+
+	return expanded, secret, scheme, expandErr
+}
+
+func (l *ViperLoader[T]) expandRef(key string, stack []string) (string, bool, string, error) {
+	if slices.Contains(stack, key) {
+		return "", false, "", fmt.Errorf("reference cycle: %s -> %s", strings.Join(stack, " -> "), key)
+	}
+
+	raw := l.Get(key)
+	if raw == nil {
+		return "", false, "", fmt.Errorf("referenced key '%s' is not set", key)
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return fmt.Sprintf("%v", raw), l.isSecretPath(key), "", nil
+	}
+
+	// 已解密的明文或已标记为 secret 的值原样使用
+	if scheme, ok := l.secretKeys[key]; ok {
+		return s, true, scheme, nil
+	}
+	// section 之外的加密值与 secret 引用不会被预先处理，此处按需取明文
+	if IsEncrypted(s) {
+		keys, err := LoadEncryptionKeys()
+		if err != nil {
+			return "", false, "", err
+		}
+		plain, err := DecryptValue(keys, s)
+		return plain, true, encAlgorithm, err
+	}
+	if r, ref, ok := l.secretRef(s); ok {
+		resolved, err := r.Resolve(ref)
+		return resolved, true, r.Scheme(), err
+	}
+
+	expanded, secret, scheme, err := l.expand(s, append(stack, key))
+	return expanded, secret || l.isSecretPath(key), scheme, err
+}
+
+// isSecretPath 判断 key 是否为 secret：对应字段或其上级字段打了 secret tag，或按 isSecretKey 的命名规则视为 secret。
+// 字段在 T 与已注册的 section 中查找，因此同样适用于 section 之外的 key
+func (l *ViperLoader[T]) isSecretPath(key string) bool {
+	if isSecretKey(key) {
+		return true
+	}
+
+	var zeroT T
+	roots := sectionTypes()
+	roots[l.section] = reflect.TypeOf(zeroT)
+	for root, t := range roots {
+		rest := key
+		if root != "" {
+			var ok bool
+			if rest, ok = strings.CutPrefix(key, root+"."); !ok {
+				continue
+			}
+		}
+		if secretPath(t, rest) {
+			return true
+		}
+	}
+	return false
+}
+
+func expandEnv(name string, def string, hasDef bool) (string, error) {
+	val, ok := os.LookupEnv(name)
+	if hasDef && val == "" {
+		return def, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("env '%s' is not set", name)
+	}
+	return val, nil
+}
+
+// interpolateError 返回指向出错 key 及其所在文件的 ErrConfigInvalid
+func (l *ViperLoader[T]) interpolateError(key string, val string, err error) error {
+	v := l.completeViolation(
+		FieldViolation{
+			Key:   key,
+			Rule:  "interpolate",
+			Param: err.Error(),
+			Value: val,
+		}, nil,
+	)
+	if source, origin := l.source(key, l.fileKeys()); source == SourceFile {
+		v.Source = origin
+	}
+	return NewErrConfigInvalidWithViolations(
+		fmt.Errorf("interpolate config '%s' error: %w", key, err),
+		[]FieldViolation{v},
+	)
+}
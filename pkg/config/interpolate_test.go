@@ -0,0 +1,224 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type interpolateTestSection struct {
+	Host  string `mapstructure:"host"`
+	Port  int    `mapstructure:"port"`
+	URL   string `mapstructure:"url"`
+	Name  string `mapstructure:"name"`
+	Token string `mapstructure:"token"`
+}
+
+func loadInterpolateTest(t *testing.T, files map[string]string) (interpolateTestSection, error) {
+	t.Helper()
+
+	dir := writeConfigFiles(t, files)
+	return Load[interpolateTestSection](
+		NewSectionLoader[interpolateTestSection]("app"), WithConfigPaths(dir), WithConfigNames("base"),
+	)
+}
+
+// configViolation 断言 err 为 ErrConfigInvalid 且第一条违规的 rule 为 rule
+func configViolation(t *testing.T, err error, rule string) FieldViolation {
+	t.Helper()
+
+	var invalid ErrConfigInvalid
+	if !errors.As(err, &invalid) {
+		t.Fatalf("error = %v, want ErrConfigInvalid", err)
+	}
+	violations := invalid.Violations()
+	if len(violations) == 0 || violations[0].Rule != rule {
+		t.Fatalf("violations = %+v, want rule '%s'", violations, rule)
+	}
+	return violations[0]
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("INTERPOLATE_TEST_HOST", "db.internal")
+	t.Setenv("INTERPOLATE_TEST_EMPTY", "")
+
+	cfg, err := loadInterpolateTest(
+		t, map[string]string{
+			"base.yaml": `
+app:
+  host: ${INTERPOLATE_TEST_HOST}
+  port: 5432
+  url: postgres://${app.host}:${app.port}/${INTERPOLATE_TEST_EMPTY:-main}
+  name: $${app.host}
+  token: ${INTERPOLATE_TEST_UNSET:-none}
+`,
+		},
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Host != "db.internal" {
+		t.Errorf("Host = %q", cfg.Host)
+	}
+	if cfg.URL != "postgres://db.internal:5432/main" {
+		t.Errorf("URL = %q", cfg.URL)
+	}
+	if cfg.Name != "${app.host}" {
+		t.Errorf("Name = %q, want escaped placeholder", cfg.Name)
+	}
+	if cfg.Token != "none" {
+		t.Errorf("Token = %q, want default", cfg.Token)
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    string
+	}{
+		{
+			name:    "self cycle",
+			content: "app:\n  url: ${app.url}\n",
+			key:     "app.url",
+			want:    "reference cycle: app.url -> app.url",
+		},
+		{
+			name:    "indirect cycle",
+			content: "app:\n  host: ${app.name}\n  name: x-${app.token}\n  token: ${app.host}\n",
+			key:     "app.host",
+			want:    "reference cycle: app.host -> app.name -> app.token -> app.host",
+		},
+		{
+			name:    "missing reference",
+			content: "app:\n  url: ${app.missing}\n",
+			key:     "app.url",
+			want:    "referenced key 'app.missing' is not set",
+		},
+		{
+			name:    "missing env",
+			content: "app:\n  url: ${INTERPOLATE_TEST_UNSET}\n",
+			key:     "app.url",
+			want:    "env 'INTERPOLATE_TEST_UNSET' is not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := loadInterpolateTest(t, map[string]string{"base.yaml": tt.content})
+				v := configViolation(t, err, "interpolate")
+				if v.Key != tt.key {
+					t.Errorf("violation key = %q, want %q", v.Key, tt.key)
+				}
+				if v.Param != tt.want {
+					t.Errorf("violation param = %q, want %q", v.Param, tt.want)
+				}
+				if filepath.Base(v.Source) != "base.yaml" {
+					t.Errorf("violation source = %q, want base.yaml", v.Source)
+				}
+			},
+		)
+	}
+}
+
+func TestInclude(t *testing.T) {
+	cfg, err := loadInterpolateTest(
+		t, map[string]string{
+			"base.yaml":   "include: [common.yaml, db.yaml]\napp:\n  host: override\n",
+			"common.yaml": "app:\n  host: common\n  name: common\n",
+			"db.yaml":     "include: port.yaml\napp:\n  url: postgres://${app.host}:${app.port}\n",
+			"port.yaml":   "app:\n  port: 6432\n",
+		},
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := interpolateTestSection{Host: "override", Port: 6432, URL: "postgres://override:6432", Name: "common"}
+	if cfg != want {
+		t.Errorf("Load() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestIncludeCycle(t *testing.T) {
+	_, err := loadInterpolateTest(
+		t, map[string]string{
+			"base.yaml": "include: a.yaml\n",
+			"a.yaml":    "include: [b.yaml]\n",
+			"b.yaml":    "include: base.yaml\n",
+		},
+	)
+	v := configViolation(t, err, "include_cycle")
+	if filepath.Base(v.Source) != "b.yaml" {
+		t.Errorf("violation source = %q, want b.yaml", v.Source)
+	}
+	if !strings.Contains(err.Error(), "config include cycle") {
+		t.Errorf("error = %v, want include cycle chain", err)
+	}
+}
+
+func TestIncludeMissing(t *testing.T) {
+	_, err := loadInterpolateTest(t, map[string]string{"base.yaml": "include: missing.yaml\n"})
+
+	var notFound ErrConfigNotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("error = %v, want ErrConfigNotFound", err)
+	}
+	if filepath.Base(notFound.LackConfigName()) != "missing.yaml" {
+		t.Errorf("LackConfigName() = %q", notFound.LackConfigName())
+	}
+}
+
+func TestInterpolateSecretReference(t *testing.T) {
+	type section struct {
+		Password string            `mapstructure:"password" secret:"true"`
+		APIToken string            `mapstructure:"api_token"`
+		Keys     map[string]string `mapstructure:"keys" secret:"true"`
+		DSN      string            `mapstructure:"dsn"`
+		Header   string            `mapstructure:"header"`
+		Signed   string            `mapstructure:"signed"`
+		Host     string            `mapstructure:"host"`
+		URL      string            `mapstructure:"url"`
+	}
+
+	dir := writeConfigFiles(
+		t, map[string]string{
+			"base.yaml": `
+app:
+  password: hunter2
+  api_token: t0ken
+  keys:
+    hmac: k3y
+  dsn: pg://u:${app.password}@h
+  header: Bearer ${app.api_token}
+  signed: ${app.keys.hmac}
+  host: db
+  url: pg://${app.host}
+`,
+		},
+	)
+
+	l := NewSectionLoader[section]("app")
+	cfg, err := Load[section](l, WithConfigPaths(dir), WithConfigNames("base"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DSN != "pg://u:hunter2@h" {
+		t.Errorf("DSN = %q, want interpolated plaintext", cfg.DSN)
+	}
+
+	entries := make(map[string]ConfigEntry)
+	for _, e := range l.Explain() {
+		entries[e.Key] = e
+	}
+	for _, key := range []string{"app.password", "app.dsn", "app.header", "app.signed"} {
+		if e := entries[key]; !e.Secret || e.Value != secretMask || e.Resolver != "" {
+			t.Errorf("Explain() %s = %+v, want masked without resolver", key, e)
+		}
+	}
+	if e := entries["app.url"]; e.Secret || e.Value != "pg://db" {
+		t.Errorf("Explain() app.url = %+v, want plain", e)
+	}
+}
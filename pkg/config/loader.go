@@ -1,14 +1,18 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
+	"go.uber.org/zap"
 )
 
 type Loader[T any] interface {
@@ -32,6 +36,33 @@ type ViperLoader[T any] struct {
 	configPaths []string
 	envPrefix   string
 	defaults    map[string]any
+	rules       []Rule[T]
+	strict      bool
+	// section 非空时只加载该 key 下的配置，见 NewSectionLoader
+	section   string
+	resolvers map[string]SecretResolver
+
+	// loadMu 保证 Load 与热更新触发的重新加载串行执行
+	loadMu      sync.Mutex
+	configFiles []string
+	configTrees []string
+	// treeKeys 按 configTrees 顺序记录每个目录源提供的 key 与对应文件
+	treeKeys []map[string]string
+	remotes  []RemoteProvider
+	// remoteKeys 按 remotes 顺序记录每个远程配置源提供的 key
+	remoteKeys []map[string]struct{}
+	// tagDefaults 记录由 default tag 注册的默认值
+	tagDefaults map[string]any
+	// secretKeys 记录通过 SecretResolver 解析或解密的 key 及其 scheme / 算法，
+	// 以及插值时引用了 secret 的 key（引用的是明文 secret 字段时 scheme 为空）
+	secretKeys map[string]string
+
+	watch   bool
+	watcher *fsnotify.Watcher
+	// stopRemotes 停止远程配置源的 Watch
+	stopRemotes context.CancelFunc
+	// log 为 nil 时使用 zap.L()，应用通过 zap.ReplaceGlobals 设置的日志同样生效
+	log *zap.Logger
 }
 
 func (l *ViperLoader[T]) SetLoaderParams(
@@ -48,126 +79,238 @@ func (l *ViperLoader[T]) SetLoaderParams(
 	l.envPrefix = envPrefix
 	l.defaults = defaults
 
-	if envPrefix != "" {
-		l.SetEnvPrefix(envPrefix)
-	}
-
 	godotenv.Load()
 
-	l.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "__"))
+	l.configure()
+}
+
+// configure 将加载参数应用到当前的 viper 实例上，热更新时会基于新实例重新调用
+func (l *ViperLoader[T]) configure() {
+	if l.envPrefix != "" {
+		l.SetEnvPrefix(l.envPrefix)
+	}
+
+	l.SetEnvKeyReplacer(envKeyReplacer)
 	//l.AutomaticEnv()
-	for k, v := range defaults {
+	for k, v := range l.defaults {
 		l.SetDefault(k, v)
 	}
 }
 
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "__")
+
+// envVarName 返回 bind 为 key 绑定的环境变量名，与 viper 的解析规则一致
+func (l *ViperLoader[T]) envVarName(key string) string {
+	name := strings.ToUpper(key)
+	if l.envPrefix != "" {
+		name = strings.ToUpper(l.envPrefix) + "_" + name
+	}
+	return envKeyReplacer.Replace(name)
+}
+
 func NewViperLoader[T any]() *ViperLoader[T] {
+	validate := validator.New()
+	// 校验错误中的字段路径使用 mapstructure 名称，与配置文件中的 key 保持一致
+	validate.RegisterTagNameFunc(
+		func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		},
+	)
+
 	return &ViperLoader[T]{
-		Viper: viper.New(), Validate: validator.New(),
+		Viper: viper.New(), Validate: validate,
+		resolvers: defaultSecretResolvers(),
 	}
 }
 
-func (l *ViperLoader[T]) bind(t reflect.Type, parent string) {
-	if t.Kind() == reflect.Pointer {
-		t = t.Elem()
+// NewSectionLoader 创建只加载 section 这一个 key 的 loader，T 为该 section 的结构体
+func NewSectionLoader[T any](section string) *ViperLoader[T] {
+	l := NewViperLoader[T]()
+	l.section = section
+	return l
+}
+
+// unmarshalSection 解码 section 下的配置。
+// viper 的 UnmarshalKey 不会合并绑定到子 key 的环境变量与默认值，因此先通过 AllSettings 展开
+func (l *ViperLoader[T]) unmarshalSection(rawVal any, opts ...viper.DecoderConfigOption) error {
+	settings := l.AllSettings()
+	for _, part := range strings.Split(l.section, ".") {
+		settings, _ = settings[part].(map[string]any)
 	}
-	if t.Kind() != reflect.Struct {
-		return
+
+	sub := viper.New()
+	if err := sub.MergeConfigMap(settings); err != nil {
+		return err
 	}
+	return sub.Unmarshal(rawVal, opts...)
+}
 
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
+// inSection 判断 key 是否属于当前 loader 负责的 section
+func (l *ViperLoader[T]) inSection(key string) bool {
+	return l.section == "" || key == l.section || strings.HasPrefix(key, l.section+".")
+}
 
-		// 跳过未导出字段 / 嵌入字段
-		if f.PkgPath != "" {
-			continue
-		}
+// SetLogger 设置 loader 在后台流程（如热更新）中使用的日志，为 nil 时使用 zap.L()。
+// logger 包依赖 config，因此这里直接使用 zap
+func (l *ViperLoader[T]) SetLogger(log *zap.Logger) {
+	l.log = log
+}
 
-		tag := f.Tag.Get("mapstructure")
-		if tag == "" || tag == "-" {
-			continue
-		}
+func (l *ViperLoader[T]) logger() *zap.Logger {
+	if l.log == nil {
+		return zap.L()
+	}
+	return l.log
+}
 
-		key := tag
-		if parent != "" {
-			key = parent + "." + tag
-		}
+func (l *ViperLoader[T]) bind(t reflect.Type, parent string) error {
+	explicit := viper.New()
+	for k, v := range l.defaults {
+		explicit.SetDefault(k, v)
+	}
 
-		if f.Type.Kind() == reflect.Struct &&
-			!(f.Type.PkgPath() == "time" && f.Type.Name() == "Duration") {
-			l.bind(f.Type, key)
-			continue
-		}
+	l.tagDefaults = make(map[string]any)
+
+	var errList []error
+	walkLeafFields(
+		t, parent, func(key string, f reflect.StructField) {
+			// 叶子：绑定环境变量，slice / map 整体也可通过 JSON 设置
+			if f.Type.Kind() == reflect.Map {
+				l.bindMapEnv(key, f.Type)
+			} else {
+				_ = l.BindEnv(key)
+			}
+
+			// defaults 参数优先于 default tag
+			if explicit.IsSet(key) {
+				return
+			}
+			val, ok, err := parseDefaultTag(f)
+			if err != nil {
+				errList = append(errList, fmt.Errorf("field '%s': %w", key, err))
+				return
+			}
+			if ok {
+				l.tagDefaults[key] = val
+				l.SetDefault(key, val)
+			}
+		},
+	)
 
-		// 叶子：绑定环境变量
-		_ = l.BindEnv(key)
-	}
+	return errors.Join(errList...)
 }
 
 func (l *ViperLoader[T]) Valid(cfg *T) error {
 	err := l.Struct(cfg)
 
-	if err == nil {
-		return nil
-	}
-
 	var invalidVE *validator.InvalidValidationError
-	var validationErrs validator.ValidationErrors
 	if errors.As(err, &invalidVE) {
-		return invalidVE
+		return NewErrConfigInvalid(invalidVE, invalidVE.Error())
 	}
 
-	if errors.As(err, &validationErrs) {
-		var validationErrorMessages []string
+	var zeroT T
+	secrets := make(map[string]bool)
+	walkLeafFields(
+		reflect.TypeOf(zeroT), l.section, func(key string, f reflect.StructField) {
+			secrets[key] = isSecretField(f)
+		},
+	)
+
+	var violations []FieldViolation
 
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
 		for _, fieldErr := range validationErrs {
-			validationErrorMessages = append(
-				validationErrorMessages,
-				fmt.Sprintf(
-					"fields '%s' valid faild: rule '%s', value is '%v'",
-					fieldErr.Namespace(),
-					fieldErr.Tag(),
-					fieldErr.Value(),
+			key := namespaceToKey(reflect.TypeOf(zeroT), fieldErr.Namespace())
+			if l.section != "" {
+				key = l.section + "." + key
+			}
+
+			violations = append(
+				violations, l.completeViolation(
+					FieldViolation{
+						Key:   key,
+						Rule:  fieldErr.Tag(),
+						Param: fieldErr.Param(),
+						Value: fieldErr.Value(),
+					}, secrets,
 				),
 			)
 		}
-		return NewErrConfigInvalid(validationErrs, strings.Join(validationErrorMessages, "\n - "))
+	} else if err != nil {
+		return err
+	}
+
+	violations = append(violations, l.ruleViolations(cfg, secrets)...)
+	if len(violations) == 0 {
+		return nil
 	}
 
-	return err
+	// 只有 Rule 失败时没有 validator 的错误，由 violations 生成错误信息，便于 panic 与日志中定位字段
+	if err == nil {
+		msgs := make([]string, 0, len(violations))
+		for _, v := range violations {
+			msgs = append(msgs, v.String())
+		}
+		err = fmt.Errorf("config rule violated: %s", strings.Join(msgs, "; "))
+	}
+	return NewErrConfigInvalidWithViolations(err, violations)
 }
 
 func (l *ViperLoader[T]) Load() (*T, error) {
+	l.loadMu.Lock()
+	defer l.loadMu.Unlock()
 
 	var cfg T
 
-	l.bind(reflect.TypeOf(cfg), "")
+	if err := l.bind(reflect.TypeOf(cfg), l.section); err != nil {
+		return nil, NewErrConfigInvalid(fmt.Errorf("config default tag error: %w", err), err.Error())
+	}
 
-	l.SetConfigType(l.configType)
+	if err := l.readConfigFiles(); err != nil {
+		return nil, err
+	}
 
-	for _, path := range l.configPaths {
-		l.AddConfigPath(path)
+	if err := l.readConfigTrees(); err != nil {
+		return nil, err
 	}
 
-	for i, name := range l.configNames {
-		var err error
-		l.SetConfigName(name)
-		if i == 0 {
-			err = l.ReadInConfig()
-		} else {
-			err = l.MergeInConfig()
-		}
+	if err := l.readRemotes(); err != nil {
+		return nil, err
+	}
 
-		var notFound viper.ConfigFileNotFoundError
-		if errors.As(err, &notFound) {
-			continue
-		} else if err != nil {
-			return nil, fmt.Errorf("read config error: %w", err)
+	if l.strict {
+		if err := l.checkUnknownKeys(); err != nil {
+			return nil, err
 		}
 	}
 
-	if err := l.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("config unmarshal error: %w", err)
+	l.secretKeys = make(map[string]string)
+
+	// 先解密与解析引用，插值中的自引用才能拿到明文
+	if err := l.decryptValues(); err != nil {
+		return nil, err
+	}
+
+	if err := l.resolveSecrets(); err != nil {
+		return nil, err
+	}
+
+	if err := l.interpolate(); err != nil {
+		return nil, err
+	}
+
+	unmarshal := l.Unmarshal
+	if l.section != "" {
+		unmarshal = l.unmarshalSection
+	}
+
+	if err := unmarshal(&cfg, decodeHook()); err != nil {
+		return nil, NewErrConfigInvalid(fmt.Errorf("config unmarshal error: %w", err), err.Error())
 	}
 
 	if err := l.Valid(&cfg); err != nil {
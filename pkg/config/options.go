@@ -0,0 +1,190 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// Option 调整 ProvideGenericsConfig 的加载参数
+type Option func(*configParam)
+
+var (
+	defaultOptionsMu sync.RWMutex
+	defaultOptions   []Option
+	// commandLine 在所有 Option 之后应用，命令行参数优先于代码中的设置
+	commandLine = WithCommandLine(os.Args[1:])
+)
+
+// SetDefaultOptions 追加全局 Option，对之后所有 ProvideGenericsConfig 调用生效。
+// 适用于通过 wire 调用 ProvideConfig、无法直接传参的场景，需在首次加载前调用
+func SetDefaultOptions(opts ...Option) {
+	defaultOptionsMu.Lock()
+	defer defaultOptionsMu.Unlock()
+
+	defaultOptions = append(defaultOptions, opts...)
+}
+
+// WithConfigNames 设置按顺序合并的配置文件名（不含扩展名），后者覆盖前者
+func WithConfigNames(names ...string) Option {
+	return func(p *configParam) {
+		p.ConfigNames = names
+	}
+}
+
+// WithConfigType 设置配置文件格式，如 yaml、json、toml
+func WithConfigType(configType string) Option {
+	return func(p *configParam) {
+		p.ConfigType = configType
+	}
+}
+
+// WithConfigPaths 设置配置文件搜索目录，相对路径会向上查找，见 resolveConfigPaths
+func WithConfigPaths(paths ...string) Option {
+	return func(p *configParam) {
+		p.ConfigPaths = paths
+	}
+}
+
+// WithEnvPrefix 设置环境变量前缀，如 APP 对应 APP_DATABASE_HOST
+func WithEnvPrefix(prefix string) Option {
+	return func(p *configParam) {
+		p.EnvPrefix = prefix
+	}
+}
+
+// WithDefaults 追加默认值，key 为点分路径，优先级高于 default tag
+func WithDefaults(defaults map[string]any) Option {
+	return func(p *configParam) {
+		for k, v := range defaults {
+			p.Defaults[k] = v
+		}
+	}
+}
+
+// WithWatch 开启热更新，loader 需实现 WatchableLoader
+func WithWatch() Option {
+	return func(p *configParam) {
+		p.Watch = true
+	}
+}
+
+// WithLogger 设置 loader 在热更新等后台流程中使用的日志，如被拒绝的重新加载。
+// 未设置时使用 zap.L()
+func WithLogger(log *zap.Logger) Option {
+	return func(p *configParam) {
+		p.Logger = log
+	}
+}
+
+// 命令行参数
+const (
+	FlagConfigDir  = "config-dir"
+	FlagConfigType = "config-type"
+	FlagEnvPrefix  = "env-prefix"
+	FlagConfigTree = "config-tree"
+)
+
+// WithCommandLine 从命令行参数中读取 --config-dir、--config-tree（可重复）、--config-type、--env-prefix，
+// 同时支持 `--flag value` 与 `--flag=value` 两种写法。
+// 只读取不消费，不影响应用自己的 flag 解析；os.Args 默认会在其他 Option 之后应用
+func WithCommandLine(args []string) Option {
+	return func(p *configParam) {
+		var dirs []string
+		for name, val := range scanFlags(args, FlagConfigDir, FlagConfigType, FlagEnvPrefix, FlagConfigTree) {
+			switch name {
+			case FlagConfigDir:
+				dirs = append(dirs, val...)
+			case FlagConfigTree:
+				p.ConfigTrees = append(p.ConfigTrees, val...)
+			case FlagConfigType:
+				p.ConfigType = val[len(val)-1]
+			case FlagEnvPrefix:
+				p.EnvPrefix = val[len(val)-1]
+			}
+		}
+		if len(dirs) > 0 {
+			p.ConfigPaths = dirs
+		}
+	}
+}
+
+func scanFlags(args []string, names ...string) map[string][]string {
+	result := make(map[string][]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, val, hasVal := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		for _, want := range names {
+			if name != want {
+				continue
+			}
+			if !hasVal {
+				if i+1 >= len(args) {
+					break
+				}
+				i++
+				val = args[i]
+			}
+			result[name] = append(result[name], val)
+		}
+	}
+	return result
+}
+
+// resolveConfigPaths 让相对路径在测试、cmd 子目录等非项目根目录下也能找到配置：
+// 依次尝试当前目录及其各级父目录（到 go.mod 所在的模块根目录为止）、可执行文件所在目录，取第一个存在的目录
+func resolveConfigPaths(paths []string) []string {
+	resolved := make([]string, 0, len(paths))
+	for _, path := range paths {
+		resolved = append(resolved, resolveConfigPath(path))
+	}
+	return resolved
+}
+
+func resolveConfigPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	var bases []string
+	if wd, err := os.Getwd(); err == nil {
+		bases = append(bases, moduleDirs(wd)...)
+	}
+	if exe, err := os.Executable(); err == nil {
+		bases = append(bases, filepath.Dir(exe))
+	}
+
+	for _, base := range bases {
+		candidate := filepath.Join(base, path)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate
+		}
+	}
+
+	return path
+}
+
+// moduleDirs 返回 wd 及其到模块根目录（含 go.mod 的目录）的各级父目录。
+// 不在 Go 模块内时只返回 wd，避免误用无关的上级目录
+func moduleDirs(wd string) []string {
+	var dirs []string
+	for dir := wd; ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dirs
+		}
+		if filepath.Dir(dir) == dir {
+			return []string{wd}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestScanFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want map[string][]string
+	}{
+		{
+			name: "space and equals",
+			args: []string{"app", "--config-dir", "a", "-config-type=toml", "--env-prefix=APP"},
+			want: map[string][]string{
+				FlagConfigDir:  {"a"},
+				FlagConfigType: {"toml"},
+				FlagEnvPrefix:  {"APP"},
+			},
+		},
+		{
+			name: "repeated",
+			args: []string{"--config-tree=/a", "--config-tree", "/b", "--config-dir=x"},
+			want: map[string][]string{
+				FlagConfigTree: {"/a", "/b"},
+				FlagConfigDir:  {"x"},
+			},
+		},
+		{
+			// 应用自己的 flag 与位置参数被忽略
+			name: "unknown flags",
+			args: []string{"--port", "8080", "config-dir", "--config-directory=x", "--config-type=yaml"},
+			want: map[string][]string{FlagConfigType: {"yaml"}},
+		},
+		{
+			name: "stop at --",
+			args: []string{"--config-dir=a", "--", "--config-dir=b"},
+			want: map[string][]string{FlagConfigDir: {"a"}},
+		},
+		{
+			name: "missing value",
+			args: []string{"--config-dir"},
+			want: map[string][]string{},
+		},
+		{
+			name: "empty value",
+			args: []string{"--env-prefix="},
+			want: map[string][]string{FlagEnvPrefix: {""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got := scanFlags(tt.args, FlagConfigDir, FlagConfigType, FlagEnvPrefix, FlagConfigTree)
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("scanFlags(%q) = %v, want %v", tt.args, got, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestWithCommandLine(t *testing.T) {
+	p := configParam{ConfigPaths: []string{"configs"}, ConfigType: "yaml"}
+	WithCommandLine(
+		[]string{"--config-dir=a", "--config-dir", "b", "--config-type=json", "--config-type=toml", "--config-tree=/t"},
+	)(&p)
+
+	if !slices.Equal(p.ConfigPaths, []string{"a", "b"}) {
+		t.Errorf("ConfigPaths = %q, want the flags to replace the defaults", p.ConfigPaths)
+	}
+	if p.ConfigType != "toml" {
+		t.Errorf("ConfigType = %q, want the last value", p.ConfigType)
+	}
+	if !slices.Equal(p.ConfigTrees, []string{"/t"}) {
+		t.Errorf("ConfigTrees = %q", p.ConfigTrees)
+	}
+}
+
+func TestModuleDirs(t *testing.T) {
+	root := t.TempDir()
+	wd := filepath.Join(root, "cmd", "app")
+	if err := os.MkdirAll(wd, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不在 Go 模块内时只返回 wd
+	if got := moduleDirs(wd); !slices.Equal(got, []string{wd}) {
+		t.Errorf("moduleDirs() outside a module = %q, want %q", got, []string{wd})
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{wd, filepath.Join(root, "cmd"), root}
+	if got := moduleDirs(wd); !slices.Equal(got, want) {
+		t.Errorf("moduleDirs() = %q, want %q", got, want)
+	}
+	if got := moduleDirs(root); !slices.Equal(got, []string{root}) {
+		t.Errorf("moduleDirs(root) = %q, want %q", got, []string{root})
+	}
+}
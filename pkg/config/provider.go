@@ -1,10 +1,15 @@
 package config
 
 import (
-	"os"
+	"errors"
+	"io"
 	"reflect"
-	"strings"
 	"sync"
+	"sync/atomic"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
 )
 
 var (
@@ -14,7 +19,7 @@ var (
 
 var (
 	configsCacheMu  sync.Mutex
-	configsCache    = make(map[reflect.Type]any)        // Cache for loaded configurations
+	configsCache    = make(map[reflect.Type]any)        // Cache for loaded configurations, value is *configHolder[T]
 	loadOncePerType = make(map[reflect.Type]*sync.Once) // Ensure single load per type
 )
 
@@ -24,40 +29,143 @@ type configParam struct {
 	ConfigPaths []string
 	EnvPrefix   string
 	Defaults    map[string]any
+	Watch       bool
+	Strict      bool
+	ConfigTrees []string
+	Validations map[string]validator.Func
+	Rules       []envRule
+	Remotes     []RemoteProvider
+	Logger      *zap.Logger
 }
 
 const ENV = "ENV"
 
-var (
-	configParamOnce sync.Once
-	param           configParam
-)
+// getConfigParam 以 ENV 推导的默认值为基础，依次应用全局 Option、调用方传入的 Option 与命令行参数
+func getConfigParam(opts ...Option) configParam {
+	env := CurrentEnv()
 
-func getConfigParam() configParam {
-	configParamOnce.Do(
-		func() {
-			env, exist := os.LookupEnv(ENV)
-
-			if !exist || env == "" {
-				env = "PROD"
-			}
-			env = strings.ToLower(env)
-
-			param = configParam{
-				ConfigNames: []string{"base", env},
-				ConfigType:  "yaml",
-				ConfigPaths: []string{"./config"},
-				EnvPrefix:   "",
-				Defaults:    make(map[string]any),
-			}
-		},
-	)
-	return param
+	p := configParam{
+		ConfigNames: []string{"base", env},
+		ConfigType:  "yaml",
+		ConfigPaths: []string{"./config"},
+		EnvPrefix:   "",
+		Defaults:    make(map[string]any),
+	}
+
+	defaultOptionsMu.RLock()
+	allOpts := append(append([]Option{}, defaultOptions...), opts...)
+	defaultOptionsMu.RUnlock()
+	allOpts = append(allOpts, commandLine)
+
+	for _, opt := range allOpts {
+		opt(&p)
+	}
+
+	p.ConfigPaths = resolveConfigPaths(p.ConfigPaths)
+	p.ConfigTrees = resolveConfigPaths(p.ConfigTrees)
+
+	return p
 }
 
-func ProvideGenericsConfig[T any](
+// configHolder 保存某一类型配置的当前值，热更新时原子替换并通知订阅者
+type configHolder[T any] struct {
+	value     atomic.Pointer[T]
+	err       error
+	explainer Explainer
+	closer    io.Closer
+
+	subMu       sync.Mutex
+	nextSubID   uint64
+	subscribers map[uint64]func(old, new T)
+}
+
+func (h *configHolder[T]) swap(cfg *T) {
+	old := h.value.Swap(cfg)
+	if old == nil || reflect.DeepEqual(*old, *cfg) {
+		return
+	}
+
+	h.subMu.Lock()
+	subscribers := make([]func(old, new T), 0, len(h.subscribers))
+	for _, fn := range h.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	h.subMu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(*old, *cfg)
+	}
+}
+
+func (h *configHolder[T]) close() {
+	if h.closer != nil {
+		_ = h.closer.Close()
+	}
+}
+
+func getConfigHolder[T any]() *configHolder[T] {
+	var zeroT T
+	configType := reflect.TypeOf(zeroT)
+
+	configsCacheMu.Lock()
+	defer configsCacheMu.Unlock()
+
+	if h, ok := configsCache[configType]; ok {
+		holder, ok := h.(*configHolder[T])
+		if !ok {
+			panic("config type mismatch")
+		}
+		return holder
+	}
+
+	holder := &configHolder[T]{subscribers: make(map[uint64]func(old, new T))}
+	configsCache[configType] = holder
+	return holder
+}
+
+// Subscribe 订阅类型 T 的配置变更，仅在开启热更新且新配置通过校验后回调。
+// 返回的函数用于取消订阅
+func Subscribe[T any](fn func(old, new T)) (unsubscribe func()) {
+	holder := getConfigHolder[T]()
+
+	holder.subMu.Lock()
+	id := holder.nextSubID
+	holder.nextSubID++
+	holder.subscribers[id] = fn
+	holder.subMu.Unlock()
+
+	return func() {
+		holder.subMu.Lock()
+		delete(holder.subscribers, id)
+		holder.subMu.Unlock()
+	}
+}
+
+// Explain 返回类型 T 生效配置的来源说明，loader 不支持 Explainer 或尚未加载时返回 nil
+func Explain[T any]() []ConfigEntry {
+	holder := getConfigHolder[T]()
+	if holder.value.Load() == nil || holder.explainer == nil {
+		return nil
+	}
+	return holder.explainer.Explain()
+}
+
+// Current 返回类型 T 当前生效的配置，热更新后会返回最新值。
+// 配置尚未通过 ProvideGenericsConfig 加载时 ok 为 false
+func Current[T any]() (cfg T, ok bool) {
+	ptr := getConfigHolder[T]().value.Load()
+	if ptr == nil {
+		return cfg, false
+	}
+	return *ptr, true
+}
+
+// LoadGenericsConfig 加载并缓存类型 T 的配置，每种类型只加载一次。
+// 失败时返回 ErrConfigNotFound / ErrConfigInvalid，且错误同样会被缓存，测试中可通过 Reset 重新加载
+func LoadGenericsConfig[T any](
 	l Loader[T],
-) T {
+	opts ...Option,
+) (T, error) {
 	var zeroT T
 	configType := reflect.TypeOf(zeroT)
 
@@ -71,47 +179,141 @@ func ProvideGenericsConfig[T any](
 
 	configsCacheMu.Unlock()
 
+	holder := getConfigHolder[T]()
+
 	once.Do(
 		func() {
-			param := getConfigParam()
-			l.SetLoaderParams(
-				param.ConfigNames,
-				param.ConfigType,
-				param.ConfigPaths,
-				param.EnvPrefix,
-				param.Defaults,
-			)
-
-			cfg, err := l.Load()
-			if err != nil {
-				panic(err)
-			}
-
-			if err := l.Valid(cfg); err != nil {
-				panic(err)
-			}
-
-			configsCacheMu.Lock()
-			configsCache[configType] = cfg
-			configsCacheMu.Unlock()
+			holder.err = loadIntoHolder(l, holder, opts...)
 		},
 	)
 
-	configsCacheMu.Lock()
-	cfg, ok := configsCache[configType]
-	configsCacheMu.Unlock()
-	if !ok {
-		panic("once done but config not found in cache")
+	if holder.err != nil {
+		return zeroT, holder.err
 	}
 
-	cfgPtr, ok := cfg.(*T)
-	if !ok {
-		panic("config type mismatch")
+	cfgPtr := holder.value.Load()
+	if cfgPtr == nil {
+		return zeroT, errs.WrapCodeError(
+			errs.ErrInternalServer,
+			errors.New("once done but config not found in cache"),
+		)
+	}
+
+	return *cfgPtr, nil
+}
+
+// Load 按 LoadGenericsConfig 的规则加载并校验，但不读写全局缓存，也不开启热更新。
+// 适用于同一类型存在多份配置的场景，如 databases 下的每个数据库
+func Load[T any](l Loader[T], opts ...Option) (T, error) {
+	cfg, _, err := loadWithParam(l, opts...)
+	if err != nil {
+		var zeroT T
+		return zeroT, err
+	}
+	return *cfg, nil
+}
+
+// loadWithParam 将 Option 合并后的参数应用到 loader 上，加载并校验
+func loadWithParam[T any](l Loader[T], opts ...Option) (*T, configParam, error) {
+	param := getConfigParam(opts...)
+	l.SetLoaderParams(
+		param.ConfigNames,
+		param.ConfigType,
+		param.ConfigPaths,
+		param.EnvPrefix,
+		param.Defaults,
+	)
+
+	if err := applyRules(l, param); err != nil {
+		return nil, param, NewErrConfigInvalid(err, err.Error())
+	}
+	if s, ok := l.(interface{ EnableStrict() }); ok && param.Strict {
+		s.EnableStrict()
+	}
+	if t, ok := l.(interface{ AddConfigTree(dir string) }); ok {
+		for _, dir := range param.ConfigTrees {
+			t.AddConfigTree(dir)
+		}
+	}
+	if s, ok := l.(interface{ SetLogger(log *zap.Logger) }); ok && param.Logger != nil {
+		s.SetLogger(param.Logger)
+	}
+	if r, ok := l.(interface{ AddRemoteProvider(p RemoteProvider) }); ok {
+		for _, p := range param.Remotes {
+			r.AddRemoteProvider(p)
+		}
+	}
+
+	cfg, err := l.Load()
+	if err != nil {
+		return nil, param, err
+	}
+
+	if err := l.Valid(cfg); err != nil {
+		return nil, param, err
 	}
 
-	return *cfgPtr
+	return cfg, param, nil
+}
+
+func loadIntoHolder[T any](l Loader[T], holder *configHolder[T], opts ...Option) error {
+	cfg, param, err := loadWithParam(l, opts...)
+	if err != nil {
+		return err
+	}
+
+	if e, ok := l.(Explainer); ok {
+		holder.explainer = e
+	}
+	if c, ok := l.(io.Closer); ok {
+		holder.closer = c
+	}
+	// 先保存再开启热更新，Watch 返回前触发的重新加载才能基于这份配置通知订阅者
+	holder.value.Store(cfg)
+
+	if wl, ok := l.(WatchableLoader[T]); ok && (param.Watch || wl.Watching()) {
+		if err := wl.Watch(holder.swap); err != nil {
+			// 错误会被缓存，Current 同样不能返回这份配置
+			holder.value.Store(nil)
+			return errs.WrapCodeError(errs.ErrResourceInitFailed, err)
+		}
+	}
+
+	return nil
+}
+
+// ProvideGenericsConfig 与 LoadGenericsConfig 相同，但加载失败时 panic
+func ProvideGenericsConfig[T any](
+	l Loader[T],
+	opts ...Option,
+) T {
+	cfg, err := LoadGenericsConfig(l, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
 }
 
 func ProvideConfig() Config {
 	return ProvideGenericsConfig[Config](NewViperLoader[Config]())
 }
+
+// LoadConfig 是 ProvideConfig 返回错误的版本，供需要传播错误的 wire injector 使用
+func LoadConfig() (Config, error) {
+	return LoadGenericsConfig[Config](NewViperLoader[Config]())
+}
+
+// Reset 清空所有已加载的配置、订阅者并停止热更新，仅用于测试
+func Reset() {
+	configsCacheMu.Lock()
+	defer configsCacheMu.Unlock()
+
+	for _, h := range configsCache {
+		if c, ok := h.(interface{ close() }); ok {
+			c.close()
+		}
+	}
+
+	configsCache = make(map[reflect.Type]any)
+	loadOncePerType = make(map[reflect.Type]*sync.Once)
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type providerTestConfig struct {
+	App struct {
+		Name string `mapstructure:"name" validate:"required"`
+		Port int    `mapstructure:"port" default:"8080"`
+	} `mapstructure:"app"`
+}
+
+func TestLoadGenericsConfig(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "app:\n  name: first\n"})
+	opts := []Option{WithConfigPaths(dir), WithConfigNames("base")}
+
+	cfg, err := LoadGenericsConfig[providerTestConfig](NewViperLoader[providerTestConfig](), opts...)
+	if err != nil {
+		t.Fatalf("LoadGenericsConfig() error = %v", err)
+	}
+	if cfg.App.Name != "first" || cfg.App.Port != 8080 {
+		t.Errorf("LoadGenericsConfig() = %+v", cfg)
+	}
+
+	// 同一类型只加载一次，之后的调用返回缓存
+	writeFile(t, filepath.Join(dir, "base.yaml"), "app:\n  name: second\n")
+	cfg, err = LoadGenericsConfig[providerTestConfig](NewViperLoader[providerTestConfig](), opts...)
+	if err != nil || cfg.App.Name != "first" {
+		t.Errorf("LoadGenericsConfig() = %+v, %v, want cached config", cfg, err)
+	}
+	if current, ok := Current[providerTestConfig](); !ok || current.App.Name != "first" {
+		t.Errorf("Current() = %+v, %v", current, ok)
+	}
+
+	Reset()
+	if _, ok := Current[providerTestConfig](); ok {
+		t.Error("Current() after Reset() ok = true")
+	}
+	cfg, err = LoadGenericsConfig[providerTestConfig](NewViperLoader[providerTestConfig](), opts...)
+	if err != nil || cfg.App.Name != "second" {
+		t.Errorf("LoadGenericsConfig() after Reset() = %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadGenericsConfigError(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "app:\n  port: 9000\n"})
+	opts := []Option{WithConfigPaths(dir), WithConfigNames("base")}
+
+	_, err := LoadGenericsConfig[providerTestConfig](NewViperLoader[providerTestConfig](), opts...)
+	var invalid ErrConfigInvalid
+	if !errors.As(err, &invalid) {
+		t.Fatalf("LoadGenericsConfig() error = %v, want ErrConfigInvalid", err)
+	}
+	if v := invalid.Violations(); len(v) != 1 || v[0].Key != "app.name" || v[0].Rule != "required" {
+		t.Errorf("Violations() = %+v", v)
+	}
+
+	// 错误同样被缓存，修复配置后需要 Reset 才会重新加载
+	writeFile(t, filepath.Join(dir, "base.yaml"), "app:\n  name: fixed\n")
+	if _, err := LoadGenericsConfig[providerTestConfig](NewViperLoader[providerTestConfig](), opts...); err == nil {
+		t.Error("LoadGenericsConfig() error = nil, want cached error")
+	}
+
+	Reset()
+	cfg, err := LoadGenericsConfig[providerTestConfig](NewViperLoader[providerTestConfig](), opts...)
+	if err != nil || cfg.App.Name != "fixed" {
+		t.Errorf("LoadGenericsConfig() after Reset() = %+v, %v", cfg, err)
+	}
+}
+
+func TestProvideGenericsConfigPanics(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+
+	defer func() {
+		err, ok := recover().(error)
+		var invalid ErrConfigInvalid
+		if !ok || !errors.As(err, &invalid) {
+			t.Errorf("recover() = %v, want ErrConfigInvalid", err)
+		}
+	}()
+
+	ProvideGenericsConfig[providerTestConfig](
+		NewViperLoader[providerTestConfig](), WithConfigPaths(t.TempDir()), WithConfigNames("base"),
+	)
+	t.Error("ProvideGenericsConfig() did not panic")
+}
+
+func TestLoadDoesNotCache(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "app:\n  name: first\n"})
+	opts := []Option{WithConfigPaths(dir), WithConfigNames("base")}
+
+	for _, want := range []string{"first", "second"} {
+		writeFile(t, filepath.Join(dir, "base.yaml"), "app:\n  name: "+want+"\n")
+		cfg, err := Load[providerTestConfig](NewViperLoader[providerTestConfig](), opts...)
+		if err != nil || cfg.App.Name != want {
+			t.Errorf("Load() = %+v, %v, want name %q", cfg, err, want)
+		}
+	}
+	if _, ok := Current[providerTestConfig](); ok {
+		t.Error("Current() after Load() ok = true, Load must not fill the cache")
+	}
+}
+
+type watchTestConfig struct {
+	App struct {
+		Name string `mapstructure:"name" validate:"required"`
+	} `mapstructure:"app"`
+}
+
+// waitForLog 等待 logs 中出现 message，超时后失败
+func waitForLog(t *testing.T, logs *observer.ObservedLogs, message string, count int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.FilterMessage(message).Len() < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("log %q not written, got %v", message, logs.All())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchReload(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+
+	file := filepath.Join(writeConfigFiles(t, map[string]string{"base.yaml": "app:\n  name: first\n"}), "base.yaml")
+	core, logs := observer.New(zap.InfoLevel)
+	opts := []Option{
+		WithConfigPaths(filepath.Dir(file)), WithConfigNames("base"), WithWatch(), WithLogger(zap.New(core)),
+	}
+
+	if _, err := LoadGenericsConfig[watchTestConfig](NewViperLoader[watchTestConfig](), opts...); err != nil {
+		t.Fatalf("LoadGenericsConfig() error = %v", err)
+	}
+
+	changes := make(chan [2]string, 10)
+	Subscribe[watchTestConfig](
+		func(old, new watchTestConfig) {
+			changes <- [2]string{old.App.Name, new.App.Name}
+		},
+	)
+
+	writeFile(t, file, "app:\n  name: second\n")
+	select {
+	case c := <-changes:
+		if c != [2]string{"first", "second"} {
+			t.Errorf("Subscribe() got %v, want [first second]", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Subscribe() not notified of the file change")
+	}
+	if cfg, _ := Current[watchTestConfig](); cfg.App.Name != "second" {
+		t.Errorf("Current() = %+v, want the reloaded config", cfg)
+	}
+
+	// 校验失败的修改被丢弃，保留上一份合法配置
+	writeFile(t, file, "app:\n  name: \"\"\n")
+	waitForLog(t, logs, "config reload rejected, keep last valid config", 1)
+	select {
+	case c := <-changes:
+		t.Errorf("Subscribe() notified of an invalid config: %v", c)
+	default:
+	}
+	if cfg, _ := Current[watchTestConfig](); cfg.App.Name != "second" {
+		t.Errorf("Current() = %+v, want the last valid config", cfg)
+	}
+
+	// Reset 停止热更新
+	reloaded := logs.FilterMessage("config reloaded").Len()
+	Reset()
+	writeFile(t, file, "app:\n  name: third\n")
+	time.Sleep(3 * reloadDebounce)
+	select {
+	case c := <-changes:
+		t.Errorf("Subscribe() notified after Reset(): %v", c)
+	default:
+	}
+	if n := logs.FilterMessage("config reloaded").Len(); n != reloaded {
+		t.Errorf("config reloaded %d times after Reset()", n-reloaded)
+	}
+}
+
+// failingWatchProvider 的 Watch 总是失败，用于模拟无法开启热更新
+type failingWatchProvider struct {
+	*MemoryProvider
+}
+
+func (failingWatchProvider) Watch(context.Context, func()) error {
+	return errors.New("watch unavailable")
+}
+
+func TestWatchError(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+
+	p := failingWatchProvider{NewMemoryProvider("failing", map[string]any{"app.name": "remote"})}
+	_, err := LoadGenericsConfig[watchTestConfig](
+		NewViperLoader[watchTestConfig](),
+		WithConfigPaths(t.TempDir()), WithConfigNames("base"), WithRemoteProvider(p), WithWatch(),
+	)
+	if err == nil {
+		t.Fatal("LoadGenericsConfig() error = nil, want watch error")
+	}
+	if cfg, ok := Current[watchTestConfig](); ok {
+		t.Errorf("Current() = %+v, true after a failed Watch", cfg)
+	}
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// remoteTimeout 为每次从远程配置源读取的超时时间
+const remoteTimeout = 5 * time.Second
+
+// RemoteProvider 是远程配置源，如 KV 存储、配置中心。
+// 优先级：配置文件 < 目录源 < 远程配置源（后添加的优先） < 环境变量
+type RemoteProvider interface {
+	// Name 用于日志与 Explain 中标识来源
+	Name() string
+	// Get 返回完整的配置树，结构与配置文件一致（嵌套 map 或点分 key）
+	Get(ctx context.Context) (map[string]any, error)
+	// Watch 注册监听后立即返回，远程配置变化时调用 onChange，ctx 取消后停止。
+	// 变化后 loader 会重新调用 Get，并与文件变化一样经过校验后通知订阅者
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// AddRemoteProvider 添加远程配置源，需在 Load 之前调用
+func (l *ViperLoader[T]) AddRemoteProvider(p RemoteProvider) {
+	l.remotes = append(l.remotes, p)
+}
+
+// WithRemoteProvider 添加远程配置源，loader 需实现 AddRemoteProvider
+func WithRemoteProvider(providers ...RemoteProvider) Option {
+	return func(p *configParam) {
+		p.Remotes = append(p.Remotes, providers...)
+	}
+}
+
+// readRemotes 在配置文件与目录源之后合并远程配置，remoteKeys 记录每个配置源提供的 key
+func (l *ViperLoader[T]) readRemotes() error {
+	l.remoteKeys = l.remoteKeys[:0]
+
+	for _, p := range l.remotes {
+		ctx, cancel := context.WithTimeout(context.Background(), remoteTimeout)
+		settings, err := p.Get(ctx)
+		cancel()
+		if err != nil {
+			return NewErrConfigNotFound(fmt.Errorf("read remote config '%s' error: %w", p.Name(), err), p.Name())
+		}
+
+		// 经 viper 规整，点分 key 与嵌套 map 均可
+		v := viper.New()
+		for key, val := range settings {
+			v.Set(key, val)
+		}
+		if err := l.MergeConfigMap(v.AllSettings()); err != nil {
+			return NewErrConfigInvalid(fmt.Errorf("merge remote config '%s' error: %w", p.Name(), err), p.Name())
+		}
+
+		keys := make(map[string]struct{})
+		for _, key := range v.AllKeys() {
+			keys[key] = struct{}{}
+		}
+		l.remoteKeys = append(l.remoteKeys, keys)
+	}
+
+	return nil
+}
+
+// watchRemotes 监听所有远程配置源，变化时写入 changed，由 watchLoop 统一防抖并重新加载
+func (l *ViperLoader[T]) watchRemotes(ctx context.Context, changed chan<- struct{}) error {
+	onChange := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	for _, p := range l.remotes {
+		if err := p.Watch(ctx, onChange); err != nil {
+			return fmt.Errorf("watch remote config '%s' error: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// MemoryProvider 是进程内的 RemoteProvider，主要用于测试，Set / Delete 后会通知 Watch
+type MemoryProvider struct {
+	name string
+
+	mu       sync.Mutex
+	values   map[string]any
+	watchers map[chan struct{}]struct{}
+}
+
+// NewMemoryProvider 创建进程内配置源，values 的 key 为点分 key
+func NewMemoryProvider(name string, values map[string]any) *MemoryProvider {
+	p := &MemoryProvider{
+		name:     name,
+		values:   make(map[string]any, len(values)),
+		watchers: make(map[chan struct{}]struct{}),
+	}
+	for k, v := range values {
+		p.values[k] = v
+	}
+	return p
+}
+
+func (p *MemoryProvider) Name() string {
+	return p.name
+}
+
+func (p *MemoryProvider) Get(context.Context) (map[string]any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make(map[string]any, len(p.values))
+	for k, v := range p.values {
+		result[k] = v
+	}
+	return result, nil
+}
+
+// Set 设置点分 key 的值并通知 Watch
+func (p *MemoryProvider) Set(key string, val any) {
+	p.mu.Lock()
+	p.values[key] = val
+	p.mu.Unlock()
+	p.notify()
+}
+
+// Delete 删除点分 key 并通知 Watch
+func (p *MemoryProvider) Delete(key string) {
+	p.mu.Lock()
+	delete(p.values, key)
+	p.mu.Unlock()
+	p.notify()
+}
+
+func (p *MemoryProvider) notify() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ch := range p.watchers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (p *MemoryProvider) Watch(ctx context.Context, onChange func()) error {
+	ch := make(chan struct{}, 1)
+
+	p.mu.Lock()
+	p.watchers[ch] = struct{}{}
+	p.mu.Unlock()
+
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			delete(p.watchers, ch)
+			p.mu.Unlock()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				onChange()
+			}
+		}
+	}()
+
+	return nil
+}
+
+// FileProvider 以单个配置文件模拟远程配置源，格式由扩展名决定，文件不存在时返回空配置。
+// 适用于测试或以 sidecar 同步远程配置到本地文件的部署方式
+type FileProvider struct {
+	path string
+}
+
+func NewFileProvider(path string) *FileProvider {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) Name() string {
+	return p.path
+}
+
+func (p *FileProvider) Get(context.Context) (map[string]any, error) {
+	settings, err := readConfigMap(p.path, "")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]any{}, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Watch 监听文件所在目录，文件被替换（rename、符号链接切换）后依然能收到事件
+func (p *FileProvider) Watch(ctx context.Context, onChange func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := watcher.Add(filepath.Dir(p.path)); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				onChange()
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+
+	return nil
+}
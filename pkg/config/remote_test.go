@@ -0,0 +1,91 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRemoteProviderPrecedence(t *testing.T) {
+	type section struct {
+		Host string `mapstructure:"host"`
+		Port int    `mapstructure:"port"`
+		Name string `mapstructure:"name"`
+	}
+
+	t.Setenv("APP_NAME", "env")
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "app:\n  host: file\n  port: 1\n  name: file\n"})
+
+	cfg, err := Load[section](
+		NewSectionLoader[section]("app"),
+		WithConfigPaths(dir), WithConfigNames("base"),
+		WithRemoteProvider(
+			NewMemoryProvider("first", map[string]any{"app.host": "first", "app.port": 2}),
+			NewMemoryProvider("second", map[string]any{"app.port": 3, "app.name": "second"}),
+		),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// 远程配置源覆盖配置文件，后添加的优先，环境变量优先于远程配置源
+	want := section{Host: "first", Port: 3, Name: "env"}
+	if cfg != want {
+		t.Errorf("Load() = %+v, want %+v", cfg, want)
+	}
+}
+
+func TestMemoryProviderReload(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+
+	p := NewMemoryProvider("memory", map[string]any{"app.name": "first"})
+	core, logs := observer.New(zap.InfoLevel)
+	if _, err := LoadGenericsConfig[watchTestConfig](
+		NewViperLoader[watchTestConfig](),
+		WithConfigPaths(t.TempDir()), WithConfigNames("base"),
+		WithRemoteProvider(p), WithWatch(), WithLogger(zap.New(core)),
+	); err != nil {
+		t.Fatalf("LoadGenericsConfig() error = %v", err)
+	}
+
+	changes := make(chan [2]string, 10)
+	Subscribe[watchTestConfig](
+		func(old, new watchTestConfig) {
+			changes <- [2]string{old.App.Name, new.App.Name}
+		},
+	)
+
+	p.Set("app.name", "second")
+	select {
+	case c := <-changes:
+		if c != [2]string{"first", "second"} {
+			t.Errorf("Subscribe() got %v, want [first second]", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Subscribe() not notified of the remote change")
+	}
+
+	// 删除必填项后校验失败，保留上一份合法配置
+	p.Delete("app.name")
+	waitForLog(t, logs, "config reload rejected, keep last valid config", 1)
+	select {
+	case c := <-changes:
+		t.Errorf("Subscribe() notified of an invalid config: %v", c)
+	default:
+	}
+	if cfg, _ := Current[watchTestConfig](); cfg.App.Name != "second" {
+		t.Errorf("Current() = %+v, want the last valid config", cfg)
+	}
+
+	// Reset 后不再监听远程配置源
+	Reset()
+	p.Set("app.name", "third")
+	time.Sleep(3 * reloadDebounce)
+	select {
+	case c := <-changes:
+		t.Errorf("Subscribe() notified after Reset(): %v", c)
+	default:
+	}
+}
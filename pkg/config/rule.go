@@ -0,0 +1,126 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Rule 是结构体级校验规则，用于表达跨字段约束。
+// 返回的 FieldViolation 中 Key 相对于 T，loader 会补全 section 前缀、环境变量名并对 secret 打码
+type Rule[T any] func(cfg *T) []FieldViolation
+
+type envRule struct {
+	envs []string
+	rule any
+}
+
+// CurrentEnv 返回 ENV 环境变量的小写值，未设置时为 prod，与配置文件名的推导规则一致
+func CurrentEnv() string {
+	env, exist := os.LookupEnv(ENV)
+	if !exist || env == "" {
+		env = "PROD"
+	}
+	return strings.ToLower(env)
+}
+
+// AddRule 注册结构体级校验规则
+func (l *ViperLoader[T]) AddRule(rule Rule[T]) {
+	l.rules = append(l.rules, rule)
+}
+
+// AddEnvRule 注册只在 ENV 属于 envs 时生效的校验规则，如生产环境禁止关闭 ssl
+func (l *ViperLoader[T]) AddEnvRule(envs []string, rule Rule[T]) {
+	l.rules = append(
+		l.rules, func(cfg *T) []FieldViolation {
+			if !slices.Contains(envs, CurrentEnv()) {
+				return nil
+			}
+			return rule(cfg)
+		},
+	)
+}
+
+// WithValidation 注册自定义 validate tag，对应 validator.RegisterValidation
+func WithValidation(tag string, fn validator.Func) Option {
+	return func(p *configParam) {
+		if p.Validations == nil {
+			p.Validations = make(map[string]validator.Func)
+		}
+		p.Validations[tag] = fn
+	}
+}
+
+// WithRule 注册结构体级校验规则，T 需与加载的配置类型一致，否则忽略
+func WithRule[T any](rule Rule[T]) Option {
+	return func(p *configParam) {
+		p.Rules = append(p.Rules, envRule{rule: rule})
+	}
+}
+
+// WithEnvRule 注册只在指定 ENV 下生效的校验规则
+func WithEnvRule[T any](envs []string, rule Rule[T]) Option {
+	return func(p *configParam) {
+		p.Rules = append(p.Rules, envRule{envs: envs, rule: rule})
+	}
+}
+
+// applyRules 将 Option 中的校验注册到 loader 上
+func applyRules[T any](l Loader[T], p configParam) error {
+	if v, ok := l.(interface {
+		RegisterValidation(tag string, fn validator.Func, callValidationEvenIfNull ...bool) error
+	}); ok {
+		for tag, fn := range p.Validations {
+			if err := v.RegisterValidation(tag, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	r, ok := l.(interface {
+		AddRule(rule Rule[T])
+		AddEnvRule(envs []string, rule Rule[T])
+	})
+	if !ok {
+		return nil
+	}
+	for _, er := range p.Rules {
+		rule, ok := er.rule.(Rule[T])
+		if !ok {
+			continue
+		}
+		if len(er.envs) == 0 {
+			r.AddRule(rule)
+		} else {
+			r.AddEnvRule(er.envs, rule)
+		}
+	}
+	return nil
+}
+
+// ruleViolations 执行所有规则并补全 violation 信息
+func (l *ViperLoader[T]) ruleViolations(cfg *T, secrets map[string]bool) []FieldViolation {
+	var violations []FieldViolation
+	for _, rule := range l.rules {
+		for _, v := range rule(cfg) {
+			if l.section != "" {
+				v.Key = l.section + "." + v.Key
+			}
+			violations = append(violations, l.completeViolation(v, secrets))
+		}
+	}
+	return violations
+}
+
+func (l *ViperLoader[T]) completeViolation(v FieldViolation, secrets map[string]bool) FieldViolation {
+	_, secretKey := l.secretKeys[v.Key]
+	if secrets[stripIndex(v.Key)] || secretKey || isSecretKey(v.Key) {
+		v.Value = secretMask
+	}
+	if v.EnvVar == "" {
+		v.EnvVar = l.envVarName(stripIndex(v.Key))
+	}
+	return v
+}
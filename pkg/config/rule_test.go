@@ -0,0 +1,91 @@
+package config
+
+import (
+	"testing"
+)
+
+type ruleTestSection struct {
+	SSLMode  string `mapstructure:"ssl_mode"`
+	Password string `mapstructure:"password" secret:"true"`
+}
+
+func sslRequired(cfg *ruleTestSection) []FieldViolation {
+	if cfg.SSLMode != "disable" {
+		return nil
+	}
+	return []FieldViolation{{Key: "ssl_mode", Rule: "ssl_required", Value: cfg.SSLMode}}
+}
+
+func loadRuleTest(t *testing.T, opts ...Option) error {
+	t.Helper()
+
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "app:\n  ssl_mode: disable\n  password: p@ss\n"})
+	_, err := Load[ruleTestSection](
+		NewSectionLoader[ruleTestSection]("app"),
+		append([]Option{WithConfigPaths(dir), WithConfigNames("base")}, opts...)...,
+	)
+	return err
+}
+
+func TestAddEnvRule(t *testing.T) {
+	tests := []struct {
+		env  string
+		want bool
+	}{
+		// 未设置 ENV 时按 prod 处理
+		{"", true},
+		{"prod", true},
+		{"PROD", true},
+		{"Staging", true},
+		{"dev", false},
+		{"production", false},
+	}
+	for _, tt := range tests {
+		t.Run(
+			"ENV="+tt.env, func(t *testing.T) {
+				t.Setenv(ENV, tt.env)
+
+				err := loadRuleTest(t, WithEnvRule([]string{"prod", "staging"}, sslRequired))
+				if !tt.want {
+					if err != nil {
+						t.Errorf("Load() error = %v, want the rule skipped", err)
+					}
+					return
+				}
+
+				v := configViolation(t, err, "ssl_required")
+				if v.Key != "app.ssl_mode" || v.EnvVar != "APP_SSL_MODE" || v.Value != "disable" {
+					t.Errorf("violation = %+v", v)
+				}
+			},
+		)
+	}
+}
+
+func TestAddRule(t *testing.T) {
+	t.Setenv(ENV, "dev")
+
+	// 不区分 ENV 的规则总是执行，secret 字段的值被打码
+	err := loadRuleTest(
+		t, WithRule(
+			func(cfg *ruleTestSection) []FieldViolation {
+				return []FieldViolation{{Key: "password", Rule: "weak_password", Value: cfg.Password}}
+			},
+		),
+	)
+	if v := configViolation(t, err, "weak_password"); v.Value != secretMask {
+		t.Errorf("violation value = %v, want masked", v.Value)
+	}
+
+	// 类型不一致的规则被忽略
+	err = loadRuleTest(
+		t, WithRule(
+			func(*struct{}) []FieldViolation {
+				return []FieldViolation{{Key: "x", Rule: "never"}}
+			},
+		),
+	)
+	if err != nil {
+		t.Errorf("Load() error = %v, want the rule for another type ignored", err)
+	}
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema 按 mapstructure / validate / default tag 生成类型 t 的 JSON Schema。
+// required 字段在有 default tag 时不再要求出现在文件中，time.Duration 以字符串表示
+func JSONSchema(t reflect.Type) map[string]any {
+	schema := typeSchema(t)
+	schema["$schema"] = schemaDraft
+	return schema
+}
+
+// FileSchema 生成完整配置文件的 JSON Schema：root 为根配置结构体（可为 nil），
+// 并合并所有通过 Section、MapSection 注册的配置段与 include 指令
+func FileSchema(root reflect.Type) map[string]any {
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           map[string]any{},
+		"additionalProperties": false,
+	}
+	if root != nil {
+		schema = typeSchema(root)
+	}
+
+	props := schema["properties"].(map[string]any)
+	for key, t := range sectionTypes() {
+		props[key] = typeSchema(t)
+	}
+	props[includeKey] = map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+	}
+
+	schema["$schema"] = schemaDraft
+	return schema
+}
+
+// MarshalSchema 将 schema 序列化为带缩进的 JSON
+func MarshalSchema(schema map[string]any) ([]byte, error) {
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == durationType {
+		return map[string]any{
+			"type":    "string",
+			"pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`,
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+func structSchema(t reflect.Type) map[string]any {
+	props := make(map[string]any)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("mapstructure"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema := typeSchema(f.Type)
+		isRequired := applyValidateTag(schema, f.Type, f.Tag.Get("validate"))
+
+		if val, ok, err := parseDefaultTag(f); err == nil && ok {
+			if f.Type == durationType {
+				schema["default"] = val.(interface{ String() string }).String()
+			} else {
+				schema["default"] = val
+			}
+			isRequired = false
+		}
+		if isSecretField(f) {
+			schema["writeOnly"] = true
+		}
+
+		props[name] = schema
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// applyValidateTag 将 validate tag 中能用 schema 表达的规则写入 schema，返回字段是否 required。
+// dive 之后的规则作用于元素，这里不再处理
+func applyValidateTag(schema map[string]any, t reflect.Type, tag string) bool {
+	required := false
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			return required
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "oneof":
+			var enum []any
+			for _, item := range strings.Fields(param) {
+				enum = append(enum, schemaValue(t, item))
+			}
+			schema["enum"] = enum
+		}
+
+		// duration 的边界为时长字符串，schema 中无法表达
+		if t == durationType {
+			continue
+		}
+
+		switch name {
+		case "min", "gte":
+			schema[boundKey(t, "min")] = schemaNumber(param)
+		case "max", "lte":
+			schema[boundKey(t, "max")] = schemaNumber(param)
+		case "gt":
+			if t.Kind() != reflect.String && t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+				schema["exclusiveMinimum"] = schemaNumber(param)
+			}
+		case "lt":
+			if t.Kind() != reflect.String && t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+				schema["exclusiveMaximum"] = schemaNumber(param)
+			}
+		case "len":
+			schema[boundKey(t, "min")] = schemaNumber(param)
+			schema[boundKey(t, "max")] = schemaNumber(param)
+		}
+	}
+
+	return required
+}
+
+// boundKey 根据字段类型返回 min / max 对应的 schema 关键字
+func boundKey(t reflect.Type, bound string) string {
+	suffix := "imum"
+	switch t.Kind() {
+	case reflect.String:
+		suffix = "Length"
+	case reflect.Slice, reflect.Array:
+		suffix = "Items"
+	case reflect.Map:
+		suffix = "Properties"
+	}
+	return bound + suffix
+}
+
+func schemaNumber(s string) any {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+func schemaValue(t reflect.Type, s string) any {
+	if t == durationType {
+		return s
+	}
+	if val, err := parseValue(t, s); err == nil {
+		return val
+	}
+	return s
+}
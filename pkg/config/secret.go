@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SecretResolver 解析形如 `scheme://ref` 的配置引用，返回真实值
+type SecretResolver interface {
+	Scheme() string
+	Resolve(ref string) (string, error)
+}
+
+// fileSecretResolver 解析 file:///run/secrets/db_pass，读取文件内容并去掉末尾换行
+type fileSecretResolver struct{}
+
+func (fileSecretResolver) Scheme() string {
+	return "file"
+}
+
+func (fileSecretResolver) Resolve(ref string) (string, error) {
+	content, err := os.ReadFile(ref)
+	if err != nil {
+		return "", fmt.Errorf("read secret file '%s' error: %w", ref, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// envSecretResolver 解析 env://OTHER_VAR，读取另一个环境变量
+type envSecretResolver struct{}
+
+func (envSecretResolver) Scheme() string {
+	return "env"
+}
+
+func (envSecretResolver) Resolve(ref string) (string, error) {
+	val, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("secret env '%s' not set", ref)
+	}
+	return val, nil
+}
+
+func defaultSecretResolvers() map[string]SecretResolver {
+	return map[string]SecretResolver{
+		"file": fileSecretResolver{},
+		"env":  envSecretResolver{},
+	}
+}
+
+// RegisterSecretResolver 注册自定义 scheme 的解析器，同名 scheme 会覆盖内置实现
+func (l *ViperLoader[T]) RegisterSecretResolver(r SecretResolver) {
+	if l.resolvers == nil {
+		l.resolvers = defaultSecretResolvers()
+	}
+	l.resolvers[r.Scheme()] = r
+}
+
+// secretRef 判断配置值是否为已注册 scheme 的引用
+func (l *ViperLoader[T]) secretRef(val string) (SecretResolver, string, bool) {
+	scheme, ref, ok := strings.Cut(val, "://")
+	if !ok {
+		return nil, "", false
+	}
+	r, ok := l.resolvers[scheme]
+	if !ok {
+		return nil, "", false
+	}
+	return r, ref, true
+}
+
+// resolveSecrets 在 Unmarshal 之前将所有引用替换为真实值
+func (l *ViperLoader[T]) resolveSecrets() error {
+	for _, key := range l.AllKeys() {
+		if !l.inSection(key) {
+			continue
+		}
+		val, ok := l.Get(key).(string)
+		if !ok {
+			continue
+		}
+
+		r, ref, ok := l.secretRef(val)
+		if !ok {
+			continue
+		}
+
+		resolved, err := r.Resolve(ref)
+		if err != nil {
+			return NewErrConfigNotFound(fmt.Errorf("resolve secret of '%s' error: %w", key, err), key)
+		}
+		l.Set(key, resolved)
+		l.secretKeys[key] = r.Scheme()
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+)
+
+type secretTestSection struct {
+	Password string `mapstructure:"password"`
+	Token    string `mapstructure:"token"`
+	APIKey   string `mapstructure:"api_key"`
+	Host     string `mapstructure:"host"`
+}
+
+// upperSecretResolver 将 ref 转为大写，用于测试自定义 scheme
+type upperSecretResolver struct{}
+
+func (upperSecretResolver) Scheme() string {
+	return "upper"
+}
+
+func (upperSecretResolver) Resolve(ref string) (string, error) {
+	return strings.ToUpper(ref), nil
+}
+
+func TestSecretRef(t *testing.T) {
+	l := NewSectionLoader[secretTestSection]("app")
+
+	tests := []struct {
+		val    string
+		scheme string
+		ref    string
+	}{
+		{"file:///run/secrets/db_pass", "file", "/run/secrets/db_pass"},
+		{"env://OTHER_VAR", "env", "OTHER_VAR"},
+		// 未注册的 scheme 与普通值不视为引用
+		{"upper://abc", "", ""},
+		{"postgres://u:p@host/db", "", ""},
+		{"plain", "", ""},
+	}
+	for _, tt := range tests {
+		r, ref, ok := l.secretRef(tt.val)
+		if ok != (tt.scheme != "") {
+			t.Errorf("secretRef(%q) ok = %v", tt.val, ok)
+			continue
+		}
+		if ok && (r.Scheme() != tt.scheme || ref != tt.ref) {
+			t.Errorf("secretRef(%q) = %s, %q, want %s, %q", tt.val, r.Scheme(), ref, tt.scheme, tt.ref)
+		}
+	}
+
+	l.RegisterSecretResolver(upperSecretResolver{})
+	if r, ref, ok := l.secretRef("upper://abc"); !ok || r.Scheme() != "upper" || ref != "abc" {
+		t.Errorf("secretRef(upper://abc) after register = %v, %q, %v", r, ref, ok)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("SECRET_TEST_TOKEN", "t0ken")
+
+	secretFile := filepath.Join(t.TempDir(), "db_pass")
+	if err := os.WriteFile(secretFile, []byte("p@ss\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := writeConfigFiles(
+		t, map[string]string{
+			"base.yaml": "app:\n" +
+				"  password: file://" + secretFile + "\n" +
+				"  token: env://SECRET_TEST_TOKEN\n" +
+				"  api_key: upper://abc\n" +
+				"  host: db.internal\n",
+		},
+	)
+
+	l := NewSectionLoader[secretTestSection]("app")
+	l.RegisterSecretResolver(upperSecretResolver{})
+	cfg, err := Load[secretTestSection](l, WithConfigPaths(dir), WithConfigNames("base"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	want := secretTestSection{Password: "p@ss", Token: "t0ken", APIKey: "ABC", Host: "db.internal"}
+	if cfg != want {
+		t.Errorf("Load() = %+v, want %+v", cfg, want)
+	}
+	for key, scheme := range map[string]string{"app.password": "file", "app.token": "env", "app.api_key": "upper"} {
+		if got, ok := l.secretKeys[key]; !ok || got != scheme {
+			t.Errorf("secretKeys[%s] = %q, %v, want %q", key, got, ok, scheme)
+		}
+	}
+	if _, ok := l.secretKeys["app.host"]; ok {
+		t.Error("secretKeys contains the plain key app.host")
+	}
+}
+
+func TestResolveSecretsError(t *testing.T) {
+	dir := writeConfigFiles(
+		t, map[string]string{
+			"base.yaml": "app:\n  token: env://SECRET_TEST_UNSET\n  host: db.internal\n",
+		},
+	)
+
+	_, err := Load[secretTestSection](
+		NewSectionLoader[secretTestSection]("app"), WithConfigPaths(dir), WithConfigNames("base"),
+	)
+
+	var notFound ErrConfigNotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Load() error = %v, want ErrConfigNotFound", err)
+	}
+	if notFound.LackConfigName() != "app.token" {
+		t.Errorf("LackConfigName() = %q, want %q", notFound.LackConfigName(), "app.token")
+	}
+	if !errs.IsErrorCode(err, errs.ErrEnvironmentConfig) {
+		t.Errorf("Load() error code = %v, want ErrEnvironmentConfig", err)
+	}
+	if !strings.Contains(err.Error(), "SECRET_TEST_UNSET") {
+		t.Errorf("Load() error = %v, want the missing env name", err)
+	}
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SectionDef 是某个包声明的配置段，key 为配置文件中的顶层 key，T 为该段的结构体。
+// 每个 section 独立加载、独立校验，互不影响
+type SectionDef[T any] struct {
+	key  string
+	opts []Option
+}
+
+type sectionEntry struct {
+	t            reflect.Type
+	newInspector func(opts ...Option) Inspector
+}
+
+// mapSectionEntry 为 MapSection 注册的配置段，t 为每个子 key 的结构体类型
+type mapSectionEntry struct {
+	t            reflect.Type
+	newInspector func(name string, opts ...Option) Inspector
+}
+
+var (
+	sectionsMu sync.Mutex
+	sections   = make(map[string]sectionEntry)
+	// mapSections 为通过 MapSection 注册的 map 形式配置段
+	mapSections = make(map[string]mapSectionEntry)
+)
+
+// Section 注册一个配置段，通常在包级变量中声明：
+//
+//	var Section = config.Section[DatabaseConfig]("database")
+//
+// 同一 key 只能对应一种类型，同一类型也只能注册到一个 key，否则 panic
+func Section[T any](key string, opts ...Option) *SectionDef[T] {
+	var zeroT T
+	t := reflect.TypeOf(zeroT)
+
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	if registered, ok := sections[key]; ok && registered.t != t {
+		panic(fmt.Sprintf("config section '%s' already registered with type %s", key, registered.t))
+	}
+	for k, registered := range sections {
+		if registered.t == t && k != key {
+			panic(fmt.Sprintf("config type %s already registered as section '%s'", t, k))
+		}
+	}
+
+	def := &SectionDef[T]{key: key, opts: opts}
+	sections[key] = sectionEntry{
+		t: t,
+		newInspector: func(opts ...Option) Inspector {
+			return &sectionInspector[T]{
+				key:    key,
+				loader: def.Loader(),
+				opts:   append(append([]Option{}, def.opts...), opts...),
+			}
+		},
+	}
+
+	return def
+}
+
+// MapSection 声明 key 下的每个子 key 都是一份 T 类型的配置，如 databases 下的各数据库。
+// 这类配置由使用方通过 SubKeys 与 Load 逐个加载，声明后 schema、严格模式、命令行工具与 SubKeys 的环境变量发现才能识别该 key。
+// opts 返回加载名为 name 的子 key 时附加的 Option（如以 name 作为某个字段的默认值），可为 nil，
+// 使用方加载时应使用同样的 Option，命令行工具的检查结果才与实际加载一致
+func MapSection[T any](key string, opts func(name string) []Option) {
+	var zeroT T
+
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	mapSections[key] = mapSectionEntry{
+		t: reflect.TypeOf(zeroT),
+		newInspector: func(name string, extra ...Option) Inspector {
+			var subOpts []Option
+			if opts != nil {
+				subOpts = opts(name)
+			}
+			sub := key + "." + name
+			return &sectionInspector[T]{
+				key:    sub,
+				loader: NewSectionLoader[T](sub),
+				opts:   append(subOpts, extra...),
+			}
+		},
+	}
+}
+
+func mapSectionType(key string) (reflect.Type, bool) {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	entry, ok := mapSections[key]
+	return entry.t, ok
+}
+
+// sectionTypes 返回已注册 section 的 key 与类型，MapSection 以 map[string]T 表示
+func sectionTypes() map[string]reflect.Type {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	result := make(map[string]reflect.Type, len(sections)+len(mapSections))
+	for k, entry := range sections {
+		result[k] = entry.t
+	}
+	for k, entry := range mapSections {
+		result[k] = reflect.MapOf(reflect.TypeOf(""), entry.t)
+	}
+	return result
+}
+
+// SectionKeys 返回排序后的已注册 section key
+func SectionKeys() []string {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	keys := make([]string, 0, len(sections))
+	for k := range sections {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// SubKeys 返回配置文件、目录源与远程配置源中 key 下的直接子 key，排序后返回，
+// 用于发现 map 形式的配置（如 databases 下的各数据库名）。
+// key 通过 MapSection 声明过时，只通过环境变量（如 DATABASES_<NAME>_HOST）配置的子 key 同样会被发现
+func SubKeys(key string, opts ...Option) ([]string, error) {
+	l, err := readSources(key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	if t, ok := mapSectionType(key); ok {
+		for sub := range mapEnvVars(l.envVarName(key)+"_", t) {
+			name, _, _ := strings.Cut(sub, ".")
+			seen[name] = struct{}{}
+		}
+	}
+	for _, k := range l.AllKeys() {
+		rest, ok := strings.CutPrefix(k, key+".")
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(rest, ".")
+		seen[name] = struct{}{}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for name := range seen {
+		keys = append(keys, name)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// readSources 读取配置文件、目录源与远程配置源，不绑定环境变量，也不解析与校验
+func readSources(section string, opts ...Option) (*ViperLoader[struct{}], error) {
+	param := getConfigParam(opts...)
+
+	l := NewSectionLoader[struct{}](section)
+	l.SetLoaderParams(param.ConfigNames, param.ConfigType, param.ConfigPaths, param.EnvPrefix, param.Defaults)
+	for _, dir := range param.ConfigTrees {
+		l.AddConfigTree(dir)
+	}
+	for _, p := range param.Remotes {
+		l.AddRemoteProvider(p)
+	}
+
+	l.loadMu.Lock()
+	defer l.loadMu.Unlock()
+
+	if err := l.readConfigFiles(); err != nil {
+		return nil, err
+	}
+	if err := l.readConfigTrees(); err != nil {
+		return nil, err
+	}
+	if err := l.readRemotes(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (s *SectionDef[T]) Key() string {
+	return s.key
+}
+
+// Loader 返回该 section 的新 loader，需要自定义时可配合 LoadGenericsConfig 使用
+func (s *SectionDef[T]) Loader() *ViperLoader[T] {
+	return NewSectionLoader[T](s.key)
+}
+
+// Load 加载并缓存该 section，语义同 LoadGenericsConfig
+func (s *SectionDef[T]) Load(opts ...Option) (T, error) {
+	return LoadGenericsConfig[T](s.Loader(), append(append([]Option{}, s.opts...), opts...)...)
+}
+
+// Provide 同 Load，但失败时 panic，语义同 ProvideGenericsConfig
+func (s *SectionDef[T]) Provide(opts ...Option) T {
+	cfg, err := s.Load(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// Inspector 是类型擦除后的 section 加载器，供命令行等工具统一检查所有已注册的 section。
+// 与 SectionDef.Load 不同，Inspector 不使用也不写入全局缓存
+type Inspector interface {
+	Key() string
+	Type() reflect.Type
+	// Load 按 ProvideGenericsConfig 的规则加载并校验
+	Load() error
+	// Explain 需在 Load 之后调用
+	Explain() []ConfigEntry
+	EnvBindings() []EnvBinding
+}
+
+type sectionInspector[T any] struct {
+	key    string
+	loader *ViperLoader[T]
+	opts   []Option
+}
+
+func (s *sectionInspector[T]) Key() string {
+	return s.key
+}
+
+func (s *sectionInspector[T]) Type() reflect.Type {
+	var zeroT T
+	return reflect.TypeOf(zeroT)
+}
+
+func (s *sectionInspector[T]) Load() error {
+	_, _, err := loadWithParam[T](s.loader, s.opts...)
+	return err
+}
+
+func (s *sectionInspector[T]) Explain() []ConfigEntry {
+	return s.loader.Explain()
+}
+
+func (s *sectionInspector[T]) EnvBindings() []EnvBinding {
+	// 环境变量名依赖 env prefix，未 Load 时需先应用参数
+	param := getConfigParam(s.opts...)
+	s.loader.SetLoaderParams(
+		param.ConfigNames,
+		param.ConfigType,
+		param.ConfigPaths,
+		param.EnvPrefix,
+		param.Defaults,
+	)
+	return s.loader.EnvBindings()
+}
+
+// Inspectors 为所有已注册的 section 创建 Inspector，按 key 排序。
+// MapSection 为 SubKeys 发现的每个子 key 各创建一个，key 为 <section>.<name>，如 databases.main
+func Inspectors(opts ...Option) ([]Inspector, error) {
+	sectionsMu.Lock()
+	inspectors := make([]Inspector, 0, len(sections))
+	for _, entry := range sections {
+		inspectors = append(inspectors, entry.newInspector(opts...))
+	}
+	mapEntries := maps.Clone(mapSections)
+	sectionsMu.Unlock()
+
+	for key, entry := range mapEntries {
+		names, err := SubKeys(key, opts...)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			inspectors = append(inspectors, entry.newInspector(name, opts...))
+		}
+	}
+
+	slices.SortFunc(
+		inspectors, func(a, b Inspector) int {
+			return strings.Compare(a.Key(), b.Key())
+		},
+	)
+	return inspectors, nil
+}
+
+// mapSectionTemplates 为每个 MapSection 创建子 key 为 <key> 的 Inspector，
+// 用于列出 DATABASES_<KEY>_HOST 这类环境变量的形式
+func mapSectionTemplates(opts ...Option) []Inspector {
+	sectionsMu.Lock()
+	defer sectionsMu.Unlock()
+
+	inspectors := make([]Inspector, 0, len(mapSections))
+	for _, entry := range mapSections {
+		inspectors = append(inspectors, entry.newInspector("<key>", opts...))
+	}
+	return inspectors
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EnableStrict 开启严格模式，配置文件中出现结构体未声明的 key 时 Load 返回 ErrConfigInvalid
+func (l *ViperLoader[T]) EnableStrict() {
+	l.strict = true
+}
+
+// WithStrict 开启严格模式，loader 需实现 EnableStrict。
+// section loader 只检查自己的 section，顶层 key 由普通 loader 与命令行工具的 validate --strict 检查
+func WithStrict() Option {
+	return func(p *configParam) {
+		p.Strict = true
+	}
+}
+
+// checkUnknownKeys 列出配置文件中无法映射到 T 的 key。
+// section loader 只检查自己的 section；普通 loader 跳过其他已注册 section 的 key
+func (l *ViperLoader[T]) checkUnknownKeys() error {
+	var zeroT T
+	known := make(map[string]struct{})
+	// map、interface 类型字段下的任意子 key 都是合法的
+	var openPrefixes []string
+	walkLeafFields(
+		reflect.TypeOf(zeroT), l.section, func(key string, f reflect.StructField) {
+			known[key] = struct{}{}
+			switch f.Type.Kind() {
+			case reflect.Map, reflect.Interface:
+				openPrefixes = append(openPrefixes, key+".")
+			}
+		},
+	)
+
+	if l.section == "" {
+		for key := range sectionTypes() {
+			openPrefixes = append(openPrefixes, key+".")
+		}
+	}
+
+	var (
+		violations []FieldViolation
+		listed     []string
+	)
+	for i, keys := range l.fileKeys() {
+		var unknown []string
+		for key := range keys {
+			if !l.inSection(key) {
+				continue
+			}
+			if _, ok := known[key]; ok {
+				continue
+			}
+			if slices.ContainsFunc(
+				openPrefixes, func(prefix string) bool {
+					return strings.HasPrefix(key, prefix)
+				},
+			) {
+				continue
+			}
+			unknown = append(unknown, key)
+		}
+
+		slices.Sort(unknown)
+		for _, key := range unknown {
+			violations = append(
+				violations, FieldViolation{
+					Key:    key,
+					Rule:   "unknown_key",
+					Source: l.configFiles[i],
+				},
+			)
+			listed = append(listed, fmt.Sprintf("'%s' in %s", key, l.configFiles[i]))
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return NewErrConfigInvalidWithViolations(
+		fmt.Errorf("unknown keys in config file: %s", strings.Join(listed, ", ")),
+		violations,
+	)
+}
+
+// checkTopLevelKeys 列出配置文件中未知的顶层 key：既不是已注册的 Section / MapSection，
+// 也不是 include 与 Config 的字段。section loader 只检查自己的 section，顶层 key 的拼写错误（如 databse）由此发现
+func checkTopLevelKeys(opts ...Option) error {
+	l, err := readSources("", opts...)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{})
+	for key := range sectionTypes() {
+		known[key] = struct{}{}
+	}
+	known[includeKey] = struct{}{}
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		if tag, _, _ := strings.Cut(t.Field(i).Tag.Get("mapstructure"), ","); tag != "" && tag != "-" {
+			known[tag] = struct{}{}
+		}
+	}
+
+	var (
+		violations []FieldViolation
+		listed     []string
+	)
+	for i, keys := range l.fileKeys() {
+		unknown := make(map[string]struct{})
+		for key := range keys {
+			top, _, _ := strings.Cut(key, ".")
+			if _, ok := known[top]; !ok {
+				unknown[top] = struct{}{}
+			}
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(unknown)) {
+			violations = append(
+				violations, FieldViolation{
+					Key:    key,
+					Rule:   "unknown_key",
+					Source: l.configFiles[i],
+				},
+			)
+			listed = append(listed, fmt.Sprintf("'%s' in %s", key, l.configFiles[i]))
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return NewErrConfigInvalidWithViolations(
+		fmt.Errorf("unknown top-level keys in config file: %s", strings.Join(listed, ", ")),
+		violations,
+	)
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// AddConfigTree 添加一个目录配置源，目录下每个文件对应一个 key，如 database/password -> database.password，
+// 文件名本身也可以是点分 key（ConfigMap 的 key 不能包含 /）。
+// 优先级：配置文件 < 目录源（后添加的优先） < 远程配置源 < 环境变量。目录不存在时跳过
+func (l *ViperLoader[T]) AddConfigTree(dir string) {
+	l.configTrees = append(l.configTrees, dir)
+}
+
+// WithConfigTree 添加目录配置源，适用于 Kubernetes 以目录挂载的 ConfigMap / Secret
+func WithConfigTree(dirs ...string) Option {
+	return func(p *configParam) {
+		p.ConfigTrees = append(p.ConfigTrees, dirs...)
+	}
+}
+
+// readConfigTrees 在配置文件之后合并目录源，treeKeys 记录每个目录提供的 key 及对应文件
+func (l *ViperLoader[T]) readConfigTrees() error {
+	l.treeKeys = l.treeKeys[:0]
+
+	for _, dir := range l.configTrees {
+		values, files, err := readConfigTree(dir)
+		if err != nil {
+			return NewErrConfigNotFound(fmt.Errorf("read config tree '%s' error: %w", dir, err), dir)
+		}
+
+		v := viper.New()
+		for key, val := range values {
+			v.Set(key, val)
+		}
+		if err := l.MergeConfigMap(v.AllSettings()); err != nil {
+			return NewErrConfigInvalid(fmt.Errorf("merge config tree '%s' error: %w", dir, err), dir)
+		}
+		l.treeKeys = append(l.treeKeys, files)
+	}
+
+	return nil
+}
+
+// readConfigTree 读取目录下的所有文件，跳过 Kubernetes 原子更新使用的 ..data 等以 .. 开头的条目与隐藏文件。
+// 文件内容去掉末尾换行后作为字符串值
+func readConfigTree(dir string) (map[string]string, map[string]string, error) {
+	values := make(map[string]string)
+	files := make(map[string]string)
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return values, files, nil
+	}
+
+	err := walkTree(
+		dir, "", func(rel string, path string) error {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			key := strings.ToLower(strings.ReplaceAll(filepath.ToSlash(rel), "/", "."))
+			values[key] = strings.TrimRight(string(content), "\r\n")
+			files[key] = path
+			return nil
+		},
+	)
+
+	return values, files, err
+}
+
+// walkTree 遍历目录，跟随符号链接（挂载的文件均为指向 ..data 的链接）
+func walkTree(dir string, rel string, fn func(rel string, path string) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			// 更新过程中链接可能短暂失效
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		child := filepath.Join(rel, name)
+		if info.IsDir() {
+			if err := walkTree(path, child, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if info.Mode()&fs.ModeType != 0 {
+			continue
+		}
+		if err := fn(child, path); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// treeDirs 返回目录源及其子目录，供热更新监听
+func (l *ViperLoader[T]) treeDirs() []string {
+	var dirs []string
+	for _, dir := range l.configTrees {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		dirs = append(dirs, dir)
+		_ = walkTreeDirs(dir, func(sub string) { dirs = append(dirs, sub) })
+	}
+	return dirs
+}
+
+func walkTreeDirs(dir string, fn func(dir string)) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			fn(path)
+			_ = walkTreeDirs(path, fn)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree 按 Kubernetes 挂载 ConfigMap / Secret 的方式写入目录：
+// 文件实际位于带时间戳的目录中，..data 链接到该目录，顶层条目再链接到 ..data 下的同名条目
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..2026_10_18_09_00_00.123")
+	for name, content := range files {
+		path := filepath.Join(data, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Base(data), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := os.ReadDir(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if err := os.Symlink(filepath.Join("..data", entry.Name()), filepath.Join(dir, entry.Name())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadConfigTree(t *testing.T) {
+	dir := writeTree(
+		t, map[string]string{
+			"password":      "p@ss\n",
+			"Database.Port": "5432",
+			"redis/host":    "cache\r\n",
+			".hidden":       "ignored",
+		},
+	)
+	// 更新过程中失效的链接被跳过
+	if err := os.Symlink(filepath.Join("..data", "missing"), filepath.Join(dir, "broken")); err != nil {
+		t.Fatal(err)
+	}
+
+	values, files, err := readConfigTree(dir)
+	if err != nil {
+		t.Fatalf("readConfigTree() error = %v", err)
+	}
+
+	wantValues := map[string]string{
+		"password":      "p@ss",
+		"database.port": "5432",
+		"redis.host":    "cache",
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Errorf("readConfigTree() values = %v, want %v", values, wantValues)
+	}
+	// 只读取顶层条目，..data 与带时间戳的目录不会重复出现
+	wantFiles := map[string]string{
+		"password":      filepath.Join(dir, "password"),
+		"database.port": filepath.Join(dir, "Database.Port"),
+		"redis.host":    filepath.Join(dir, "redis", "host"),
+	}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("readConfigTree() files = %v, want %v", files, wantFiles)
+	}
+}
+
+func TestReadConfigTreeMissing(t *testing.T) {
+	values, files, err := readConfigTree(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(values) != 0 || len(files) != 0 {
+		t.Errorf("readConfigTree() of a missing dir = %v, %v, %v", values, files, err)
+	}
+}
+
+func TestLoadConfigTree(t *testing.T) {
+	type section struct {
+		Host     string `mapstructure:"host"`
+		Port     int    `mapstructure:"port"`
+		Password string `mapstructure:"password"`
+	}
+
+	dir := writeConfigFiles(t, map[string]string{"base.yaml": "app:\n  host: file\n  port: 1\n  password: file\n"})
+	first := writeTree(t, map[string]string{"app.host": "first", "app/password": "first"})
+	second := writeTree(t, map[string]string{"app.password": "second", "app.port": "5432"})
+
+	cfg, err := Load[section](
+		NewSectionLoader[section]("app"),
+		WithConfigPaths(dir), WithConfigNames("base"), WithConfigTree(first, second),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	// 目录源覆盖配置文件，后添加的目录优先
+	want := section{Host: "first", Port: 5432, Password: "second"}
+	if cfg != want {
+		t.Errorf("Load() = %+v, want %+v", cfg, want)
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// reloadDebounce 合并编辑器保存、ConfigMap 符号链接切换等场景下短时间内的多次文件事件
+const reloadDebounce = 200 * time.Millisecond
+
+// WatchableLoader 是支持热更新的 Loader。
+// ProvideGenericsConfig 在首次加载成功后，若 Watching 返回 true 或传入了 WithWatch 则调用 Watch
+type WatchableLoader[T any] interface {
+	Loader[T]
+	Watching() bool
+	Watch(onChange func(cfg *T)) error
+}
+
+// EnableWatch 开启热更新，需在 ProvideGenericsConfig 之前调用
+func (l *ViperLoader[T]) EnableWatch() {
+	l.watch = true
+}
+
+func (l *ViperLoader[T]) Watching() bool {
+	return l.watch
+}
+
+// Watch 监听配置目录与远程配置源，变化后重新读取并校验配置。
+// 校验失败的配置会被记录并丢弃，onChange 只会收到通过校验的新配置
+func (l *ViperLoader[T]) Watch(onChange func(cfg *T)) error {
+	if l.watcher != nil {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("create config watcher error: %w", err)
+	}
+
+	for _, dir := range l.watchDirs() {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("watch config dir '%s' error: %w", dir, err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	remoteChanged := make(chan struct{}, 1)
+	if err := l.watchRemotes(ctx, remoteChanged); err != nil {
+		cancel()
+		_ = watcher.Close()
+		return err
+	}
+
+	l.watcher = watcher
+	l.stopRemotes = cancel
+	go l.watchLoop(watcher, remoteChanged, onChange)
+
+	return nil
+}
+
+// Close 停止热更新
+func (l *ViperLoader[T]) Close() error {
+	if l.watcher == nil {
+		return nil
+	}
+	l.stopRemotes()
+	err := l.watcher.Close()
+	l.watcher = nil
+	return err
+}
+
+// watchDirs 监听目录而不是文件本身，文件被替换（rename、符号链接切换）后依然能收到事件。
+// Kubernetes 挂载更新时会原子切换目录下的 ..data 链接，同样会在被监听的目录上产生事件
+func (l *ViperLoader[T]) watchDirs() []string {
+	seen := make(map[string]struct{})
+	var dirs []string
+
+	add := func(dir string) {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return
+		}
+		if _, ok := seen[abs]; ok {
+			return
+		}
+		seen[abs] = struct{}{}
+		dirs = append(dirs, abs)
+	}
+
+	for _, path := range l.configPaths {
+		add(path)
+	}
+	for _, file := range l.configFiles {
+		add(filepath.Dir(file))
+	}
+	for _, dir := range l.treeDirs() {
+		add(dir)
+	}
+
+	return dirs
+}
+
+func (l *ViperLoader[T]) watchLoop(
+	watcher *fsnotify.Watcher,
+	remoteChanged <-chan struct{},
+	onChange func(cfg *T),
+) {
+	var (
+		timer  *time.Timer
+		reload = make(chan struct{}, 1)
+	)
+
+	// 文件与远程配置源的变化共用同一个防抖定时器
+	schedule := func() {
+		if timer != nil {
+			timer.Stop()
+		}
+		timer = time.AfterFunc(
+			reloadDebounce, func() {
+				select {
+				case reload <- struct{}{}:
+				default:
+				}
+			},
+		)
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) &&
+				!event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			schedule()
+
+		case <-remoteChanged:
+			schedule()
+
+		case <-reload:
+			cfg, err := l.reload()
+			if err != nil {
+				l.logger().Error(
+					"config reload rejected, keep last valid config",
+					zap.String("type", fmt.Sprintf("%T", cfg)),
+					zap.Error(err),
+				)
+				continue
+			}
+			l.logger().Info("config reloaded", zap.Strings("files", l.configFiles))
+			onChange(cfg)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			l.logger().Warn("config watcher error", zap.Error(err))
+		}
+	}
+}
+
+// reload 基于新的 viper 实例重新加载，避免已删除的 key 残留在旧实例中
+func (l *ViperLoader[T]) reload() (*T, error) {
+	l.loadMu.Lock()
+	prev := l.Viper
+	l.Viper = viper.New()
+	l.configure()
+	l.loadMu.Unlock()
+
+	cfg, err := l.Load()
+	if err != nil {
+		l.loadMu.Lock()
+		l.Viper = prev
+		l.loadMu.Unlock()
+		return nil, err
+	}
+
+	return cfg, nil
+}
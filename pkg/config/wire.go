@@ -13,3 +13,10 @@ func InitializeConfig() Config {
 	)
 	return Config{}
 }
+
+func InitializeConfigWithError() (Config, error) {
+	wire.Build(
+		LoadConfig,
+	)
+	return Config{}, nil
+}
@@ -1,24 +1,56 @@
 package db
 
-import "time"
+import (
+	"time"
+
+	"terraqt.io/colas/bedrock-go/pkg/config"
+)
 
 // DatabaseConfig 数据库连接配置
 type DatabaseConfig struct {
-	Name            string        `mapstructure:"name" validate:"required"`
-	Driver          string        `mapstructure:"driver" validate:"required,oneof=postgres mysql sqlite"`
-	Host            string        `mapstructure:"host" validate:"required"`
-	Port            int32         `mapstructure:"port" validate:"required"`
-	Username        string        `mapstructure:"username"`
-	Password        string        `mapstructure:"password"`
-	Database        string        `mapstructure:"database" validate:"required"`
-	SSLMode         string        `mapstructure:"ssl_mode"`
-	MaxOpenConns    int32         `mapstructure:"max_open_conns"`
-	MaxIdleConns    int32         `mapstructure:"max_idle_conns"`
-	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
-	ConnMaxIdleTime time.Duration `mapstructure:"conn_max_idle_time"`
-	DebugSQL        bool          `mapstructure:"debug_sql"`
+	Name                 string        `mapstructure:"name" validate:"required"`
+	Driver               string        `mapstructure:"driver" validate:"required,oneof=postgres mysql sqlite" default:"postgres"`
+	Host                 string        `mapstructure:"host" validate:"required_unless=Driver sqlite"`
+	Port                 int32         `mapstructure:"port" validate:"gte=0,lte=65535"` // 为 0 时使用驱动的默认端口
+	Username             string        `mapstructure:"username"`
+	Password             string        `mapstructure:"password" secret:"true"`
+	Database             string        `mapstructure:"database" validate:"required"` // sqlite 时为文件路径或 :memory:
+	SSLMode              string        `mapstructure:"ssl_mode" default:"prefer"`
+	MaxOpenConns         int32         `mapstructure:"max_open_conns" default:"10"`
+	MaxIdleConns         int32         `mapstructure:"max_idle_conns" default:"5"`
+	ConnMaxLifetime      time.Duration `mapstructure:"conn_max_lifetime" default:"5m"`
+	ConnMaxIdleTime      time.Duration `mapstructure:"conn_max_idle_time"`
+	DebugSQL             bool          `mapstructure:"debug_sql"`
+	SlowQueryThreshold   time.Duration `mapstructure:"slow_query_threshold" default:"500ms"`                            // 超过时以 Warn 级别记录，为 0 时不记录
+	SlowQueryArgs        string        `mapstructure:"slow_query_args" validate:"oneof=none mask plain" default:"mask"` // 慢查询日志中参数的输出方式
+	Replicas             []string      `mapstructure:"replicas" validate:"dive,required"`                               // 只读副本的 host 或 host:port，仅 postgres，端口默认同 Port
+	ReplicaMaxLag        time.Duration `mapstructure:"replica_max_lag" default:"10s"`                                   // 复制延迟超过时读请求回退到主库，为 0 时不检查延迟
+	ReplicaCheckInterval time.Duration `mapstructure:"replica_check_interval" default:"5s"`
+}
+
+// productionRule 同时应用于 database 段与 databases 下的每个数据库
+var productionRule = config.WithEnvRule([]string{"prod", "production"}, requireSSLInProduction)
+
+var Section = config.Section[DatabaseConfig]("database", productionRule)
+
+// requireSSLInProduction 生产环境禁止关闭 ssl
+func requireSSLInProduction(cfg *DatabaseConfig) []config.FieldViolation {
+	if cfg.Driver != DriverPostgres || cfg.SSLMode != "disable" {
+		return nil
+	}
+	return []config.FieldViolation{
+		{Key: "ssl_mode", Rule: "ssl_required_in_production", Value: cfg.SSLMode},
+	}
 }
 
 type ConfigGetter interface {
 	GetDbConfig() DatabaseConfig
 }
+
+func (c DatabaseConfig) GetDbConfig() DatabaseConfig {
+	return c
+}
+
+func provideDatabaseConfig() (DatabaseConfig, error) {
+	return Section.Load()
+}
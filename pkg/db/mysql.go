@@ -0,0 +1,46 @@
+package db
+
+import (
+	"fmt"
+	"net/url"
+
+	"entgo.io/ent/dialect"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+// newMySQLDB 创建 MySQL 连接池，需在 main 包中引入 github.com/go-sql-driver/mysql
+func newMySQLDB(cfg DatabaseConfig, log logger.Logger) (DB, error) {
+	return openSQLDB(cfg, "mysql", mysqlDSN(cfg), dialect.MySQL, log)
+}
+
+// mysqlDSN 按 go-sql-driver/mysql 的格式拼接 DSN，ssl_mode 映射为 tls 参数
+func mysqlDSN(cfg DatabaseConfig) string {
+	port := cfg.Port
+	if port == 0 {
+		port = 3306
+	}
+
+	params := url.Values{}
+	params.Set("parseTime", "true")
+	params.Set("loc", "UTC")
+	switch cfg.SSLMode {
+	case "", "disable":
+		params.Set("tls", "false")
+	case "require":
+		params.Set("tls", "skip-verify")
+	case "verify-ca", "verify-full":
+		params.Set("tls", "true")
+	default:
+		params.Set("tls", "preferred")
+	}
+
+	return fmt.Sprintf(
+		"%s:%s@tcp(%s:%d)/%s?%s",
+		cfg.Username,
+		cfg.Password,
+		cfg.Host,
+		port,
+		cfg.Database,
+		params.Encode(),
+	)
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+)
+
+// 常用 SQLSTATE，见 https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	sqlStateNotNullViolation    = "23502"
+	sqlStateForeignKeyViolation = "23503"
+	sqlStateUniqueViolation     = "23505"
+	sqlStateCheckViolation      = "23514"
+	sqlStateExclusionViolation  = "23P01"
+	sqlStateSerial              = "40001"
+	sqlStateDeadlock            = "40P01"
+	sqlStateQueryCanceled       = "57014"
+	sqlStateAdminShutdown       = "57P01"
+	sqlStateCrashShutdown       = "57P02"
+	sqlStateCannotConnectNow    = "57P03"
+	sqlStateTooManyConnections  = "53300"
+	sqlStateDataCorrupted       = "XX001"
+	sqlStateIndexCorrupted      = "XX002"
+)
+
+// PGErrorDetail 是 Postgres 错误中可供业务使用的信息，如根据 Constraint 返回具体的 409 提示
+type PGErrorDetail struct {
+	SQLState   string
+	Constraint string
+	Schema     string
+	Table      string
+	Column     string
+	Message    string
+	Detail     string
+}
+
+// ErrorDetail 从错误链中取出 Postgres 错误的详细信息
+func ErrorDetail(err error) (PGErrorDetail, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return PGErrorDetail{}, false
+	}
+	return PGErrorDetail{
+		SQLState:   pgErr.Code,
+		Constraint: pgErr.ConstraintName,
+		Schema:     pgErr.SchemaName,
+		Table:      pgErr.TableName,
+		Column:     pgErr.ColumnName,
+		Message:    pgErr.Message,
+		Detail:     pgErr.Detail,
+	}, true
+}
+
+// translatePGError 按 SQLSTATE 将 Postgres 错误转换为 errs 错误码，原始错误保留在错误链中，
+// 可通过 ErrorDetail 或 errors.As(*pgconn.PgError) 取得。无法识别的错误原样返回
+func translatePGError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var coded errs.CodedError
+	if errors.As(err, &coded) {
+		return err
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return wrapPGError(pgErr, err)
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return err
+	case errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err):
+		return errs.WrapCodeError(errs.ErrServerProcessingTimeout, err)
+	case isConnectionLost(err):
+		return errs.WrapCodeError(errs.ErrDBConnection, fmt.Errorf("database connection lost: %w", err))
+	}
+	return err
+}
+
+// wrapPGError 按 SQLSTATE 选择错误码，唯一约束冲突为 ErrConflict，其余约束为 ErrDBConstraint
+func wrapPGError(pgErr *pgconn.PgError, err error) error {
+	code := errs.ErrUnknown
+	switch sqlState := pgErr.Code; {
+	case sqlState == sqlStateUniqueViolation, sqlState == sqlStateExclusionViolation:
+		code = errs.ErrConflict
+	case sqlState == sqlStateForeignKeyViolation, sqlState == sqlStateCheckViolation,
+		sqlState == sqlStateNotNullViolation:
+		code = errs.ErrDBConstraint
+	case sqlState == sqlStateDeadlock:
+		code = errs.ErrDBDeadlock
+	case sqlState == sqlStateSerial:
+		code = errs.ErrConcurrencyConflict
+	case sqlState == sqlStateQueryCanceled:
+		code = errs.ErrServerProcessingTimeout
+	case sqlState == sqlStateAdminShutdown, sqlState == sqlStateCrashShutdown,
+		sqlState == sqlStateCannotConnectNow, sqlState == sqlStateTooManyConnections,
+		// 08 类为连接异常
+		strings.HasPrefix(sqlState, "08"):
+		code = errs.ErrDBConnection
+	case sqlState == sqlStateDataCorrupted, sqlState == sqlStateIndexCorrupted,
+		// 22 类为数据异常，如格式错误、越界、截断
+		strings.HasPrefix(sqlState, "22"):
+		code = errs.ErrDataCorruption
+	default:
+		return err
+	}
+
+	return errs.WrapCodeError(code, describePGError(pgErr), err)
+}
+
+func describePGError(pgErr *pgconn.PgError) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "postgres error %s", pgErr.Code)
+	if pgErr.TableName != "" {
+		fmt.Fprintf(&b, " on table '%s'", pgErr.TableName)
+	}
+	if pgErr.ColumnName != "" {
+		fmt.Fprintf(&b, " column '%s'", pgErr.ColumnName)
+	}
+	if pgErr.ConstraintName != "" {
+		fmt.Fprintf(&b, " constraint '%s'", pgErr.ConstraintName)
+	}
+	return errors.New(b.String())
+}
+
+// isConnectionLost 判断是否为连接中断，此时语句是否执行成功未知
+func isConnectionLost(err error) bool {
+	var netErr net.Error
+	var connectErr *pgconn.ConnectError
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed)
+}
+
+// translatedRows 转换读取结果时返回的错误，Postgres 的约束等错误通常在读取阶段才返回
+type translatedRows struct {
+	pgx.Rows
+}
+
+func (r translatedRows) Err() error {
+	return translatePGError(r.Rows.Err())
+}
+
+func (r translatedRows) Scan(dest ...any) error {
+	return translatePGError(r.Rows.Scan(dest...))
+}
+
+type translatedRow struct {
+	pgx.Row
+}
+
+func (r translatedRow) Scan(dest ...any) error {
+	return translatePGError(r.Row.Scan(dest...))
+}
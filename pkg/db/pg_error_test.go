@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+)
+
+// errCode 返回错误链中首个 errs 错误码的名称，没有时返回空字符串
+func errCode(err error) string {
+	var coded errs.CodedError
+	if !errors.As(err, &coded) {
+		return ""
+	}
+	return coded.Code().String()
+}
+
+func TestTranslatePGError(t *testing.T) {
+	tests := []struct {
+		sqlState string
+		want     string
+	}{
+		{"23505", errs.ErrConflict.String()},
+		{"23P01", errs.ErrConflict.String()},
+		{"23503", errs.ErrDBConstraint.String()},
+		{"23514", errs.ErrDBConstraint.String()},
+		{"23502", errs.ErrDBConstraint.String()},
+		{"40P01", errs.ErrDBDeadlock.String()},
+		{"40001", errs.ErrConcurrencyConflict.String()},
+		{"57014", errs.ErrServerProcessingTimeout.String()},
+		{"08000", errs.ErrDBConnection.String()},
+		{"08006", errs.ErrDBConnection.String()},
+		{"57P01", errs.ErrDBConnection.String()},
+		{"53300", errs.ErrDBConnection.String()},
+		{"XX001", errs.ErrDataCorruption.String()},
+		{"XX002", errs.ErrDataCorruption.String()},
+		{"22001", errs.ErrDataCorruption.String()},
+		// 无法识别的 SQLSTATE 原样返回
+		{"42601", ""},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.sqlState, func(t *testing.T) {
+				pgErr := &pgconn.PgError{Code: tt.sqlState, Message: "test"}
+				err := translatePGError(fmt.Errorf("query error: %w", pgErr))
+
+				if got := errCode(err); got != tt.want {
+					t.Errorf("translatePGError() code = %q, want %q", got, tt.want)
+				}
+				var got *pgconn.PgError
+				if !errors.As(err, &got) || got != pgErr {
+					t.Errorf("translatePGError() lost the *pgconn.PgError: %v", err)
+				}
+			},
+		)
+	}
+}
+
+func TestTranslatePGErrorPassThrough(t *testing.T) {
+	coded := errs.WrapCodeError(errs.ErrConflict, errors.New("already coded"))
+
+	for _, err := range []error{nil, pgx.ErrNoRows, context.Canceled, coded} {
+		if got := translatePGError(err); got != err {
+			t.Errorf("translatePGError(%v) = %v, want the error unchanged", err, got)
+		}
+	}
+}
+
+func TestTranslatePGErrorConnection(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, errs.ErrServerProcessingTimeout.String()},
+		{io.EOF, errs.ErrDBConnection.String()},
+		{fmt.Errorf("read: %w", io.ErrUnexpectedEOF), errs.ErrDBConnection.String()},
+	}
+	for _, tt := range tests {
+		err := translatePGError(tt.err)
+		if got := errCode(err); got != tt.want {
+			t.Errorf("translatePGError(%v) code = %q, want %q", tt.err, got, tt.want)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("translatePGError(%v) lost the cause", tt.err)
+		}
+	}
+}
+
+func TestErrorDetailThroughWrap(t *testing.T) {
+	pgErr := &pgconn.PgError{
+		Code:           "23505",
+		Message:        "duplicate key value violates unique constraint",
+		Detail:         "Key (email)=(a@b.c) already exists.",
+		SchemaName:     "public",
+		TableName:      "users",
+		ColumnName:     "email",
+		ConstraintName: "users_email_key",
+	}
+	err := translatePGError(pgErr)
+
+	detail, ok := ErrorDetail(err)
+	if !ok {
+		t.Fatalf("ErrorDetail(%v) ok = false", err)
+	}
+	want := PGErrorDetail{
+		SQLState:   "23505",
+		Constraint: "users_email_key",
+		Schema:     "public",
+		Table:      "users",
+		Column:     "email",
+		Message:    pgErr.Message,
+		Detail:     pgErr.Detail,
+	}
+	if detail != want {
+		t.Errorf("ErrorDetail() = %+v, want %+v", detail, want)
+	}
+
+	if _, ok := ErrorDetail(errors.New("plain")); ok {
+		t.Error("ErrorDetail() of a non-postgres error ok = true")
+	}
+}
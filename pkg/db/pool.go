@@ -9,25 +9,24 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 	"sync"
+	"sync/atomic"
 	"terraqt.io/colas/bedrock-go/pkg/errs"
 	"terraqt.io/colas/bedrock-go/pkg/logger"
-	"terraqt.io/colas/bedrock-go/pkg/typedsyncmap"
 	"time"
 )
 
-var (
-	poolErr error
-)
-
+// poolEntry 为 Registry 中某个名称的连接，once 保证只创建一次，创建失败时缓存 err。
+// pool 仅在 Postgres 时存在，db 对所有驱动都存在。ready 在创建成功后置位，供统计等只读遍历使用
 type poolEntry struct {
-	once *sync.Once
-	pool PGPool
-	err  error
+	once   *sync.Once
+	name   string
+	driver string
+	pool   PGPool
+	db     DB
+	err    error
+	ready  atomic.Bool
 }
 
-var poolMap = typedsyncmap.NewTypedSyncMap[string, poolEntry]()
-var poolMapMutex = &sync.Mutex{}
-
 type PGPool interface {
 	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
 	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
@@ -43,46 +42,39 @@ type adaptPool interface {
 	getStdPool() *pgxpool.Pool
 }
 
-type contextKey string
-
-const queryStartTimeKey contextKey = "queryStartTime"
-
-type sqlTracer struct {
-	logger.Logger
+type postgresPool struct {
+	*pgxpool.Pool
+	log logger.Logger
 }
 
-func (s *sqlTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
-	startTime := time.Now()
-	s.Debug(ctx, "SQL execution started", zap.String("sql", data.SQL))
-	return context.WithValue(ctx, queryStartTimeKey, startTime)
+func (p *postgresPool) getStdPool() *pgxpool.Pool {
+	return p.Pool
 }
 
-func (s *sqlTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
-	startTime, ok := ctx.Value(queryStartTimeKey).(time.Time)
-	if !ok {
-		s.Warn(ctx, "Failed to get query start time from context")
-		return
-	}
+func newPostgresPool(config DatabaseConfig, log logger.Logger) (PGPool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if data.Err != nil {
-		s.Error(ctx, "SQL execution failed", zap.Error(data.Err))
-		return
+	if config.DebugSQL {
+		log.Info(nil, "SQL debug mode is enabled")
 	}
 
-	duration := time.Since(startTime)
-	s.Debug(ctx, "SQL execution completed", zap.Duration("duration", duration))
-}
+	pool, err := connectPostgres(ctx, config, log)
+	if err != nil {
+		log.Error(nil, "failed to connect postgres database", zap.Error(err))
+		return nil, err
+	}
 
-type postgresPool struct {
-	*pgxpool.Pool
-	log logger.Logger
-}
+	log.Info(nil, "Successfully connected to database")
 
-func (p *postgresPool) getStdPool() *pgxpool.Pool {
-	return p.Pool
+	return pool, nil
 }
 
-func newPostgresPool(config DatabaseConfig, log logger.Logger) (PGPool, error) {
+// connectPostgres 创建连接池并 ping，创建与 ping 均受 ctx 控制，失败时不记录日志，由调用方决定如何记录
+func connectPostgres(ctx context.Context, config DatabaseConfig, log logger.Logger) (*postgresPool, error) {
+	if config.Port == 0 {
+		config.Port = 5432
+	}
 
 	connString := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
@@ -96,7 +88,6 @@ func newPostgresPool(config DatabaseConfig, log logger.Logger) (PGPool, error) {
 
 	pgxConfig, err := pgxpool.ParseConfig(connString)
 	if err != nil {
-		log.Error(nil, "failed to parse postgres connection string", zap.Error(err))
 		return nil, errs.WrapCodeError(
 			errs.ErrInvalidParam,
 			fmt.Errorf("failed to parse postgres connection string: %w", err),
@@ -111,17 +102,15 @@ func newPostgresPool(config DatabaseConfig, log logger.Logger) (PGPool, error) {
 
 	pgxConfig.HealthCheckPeriod = 1 * time.Minute
 
+	tracer := newPGTracer(config)
+	tracer.layers = append(tracer.layers, newSlowQueryLayer(config, log))
 	if config.DebugSQL {
-		pgxConfig.ConnConfig.Tracer = &sqlTracer{log}
-		log.Info(nil, "SQL debug mode is enabled")
+		tracer.layers = append(tracer.layers, &logLayer{log})
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	pgxConfig.ConnConfig.Tracer = tracer
 
 	dbPool, err := pgxpool.NewWithConfig(ctx, pgxConfig)
 	if err != nil {
-		log.Error(nil, "failed to create postgres connection pool", zap.Error(err))
 		return nil, errs.WrapCodeError(
 			errs.ErrResourceInitFailed,
 			fmt.Errorf("failed to create postgres connection pool: %w", err),
@@ -130,7 +119,6 @@ func newPostgresPool(config DatabaseConfig, log logger.Logger) (PGPool, error) {
 
 	// 验证连接池是否可以正常连接
 	if err := dbPool.Ping(ctx); err != nil {
-		log.Error(nil, "failed to ping postgres database", zap.Error(err))
 		dbPool.Close()
 		return nil, errs.WrapCodeError(
 			errs.ErrDBConnection,
@@ -138,35 +126,56 @@ func newPostgresPool(config DatabaseConfig, log logger.Logger) (PGPool, error) {
 		)
 	}
 
-	log.Info(nil, "Successfully connected to database")
-
 	return &postgresPool{
 		Pool: dbPool,
 		log:  log,
 	}, nil
 }
 
-func providePostgresPool(dbConfig ConfigGetter, log logger.Logger) (PGPool, error) {
+// providePostgresPool 将 database 段注册到 Registry，按 DatabaseConfig.Name 复用同一个连接池
+func providePostgresPool(dbConfig ConfigGetter, registry *Registry) (PGPool, error) {
 	cfg := dbConfig.GetDbConfig()
-	poolKey := cfg.Name
+	registry.Register(cfg)
+	return registry.Get(cfg.Name)
+}
 
-	if entry, ok := poolMap.Load(poolKey); ok {
-		return entry.pool, nil
+// Exec 执行 SQL，ctx 中有 WithTx 开启的事务时在事务中执行，错误按 SQLSTATE 转换为 errs 错误码
+func (p *postgresPool) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	var (
+		tag pgconn.CommandTag
+		err error
+	)
+	if tx, ok := txFromContext(ctx, p.Pool); ok {
+		tag, err = tx.Exec(ctx, sql, args...)
+	} else {
+		tag, err = p.Pool.Exec(ctx, sql, args...)
 	}
+	return tag, translatePGError(err)
+}
 
-	poolMapMutex.Lock()
-	defer poolMapMutex.Unlock()
-
-	entry := poolEntry{}
-	// TODO
+// Query 查询，ctx 中有 WithTx 开启的事务时在事务中执行，错误转换同 Exec
+func (p *postgresPool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+	if tx, ok := txFromContext(ctx, p.Pool); ok {
+		rows, err = tx.Query(ctx, sql, args...)
+	} else {
+		rows, err = p.Pool.Query(ctx, sql, args...)
+	}
 	if err != nil {
-		log.Error(nil, "failed to create postgres connection pool", zap.Error(err))
-		return nil, errs.WrapCodeError(
-			errs.ErrResourceInitFailed,
-			fmt.Errorf("failed to create postgres connection pool: %w", err),
-		)
+		return nil, translatePGError(err)
 	}
+	return translatedRows{rows}, nil
+}
 
+// QueryRow 查询单行，ctx 中有 WithTx 开启的事务时在事务中执行，错误转换同 Exec
+func (p *postgresPool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if tx, ok := txFromContext(ctx, p.Pool); ok {
+		return translatedRow{tx.QueryRow(ctx, sql, args...)}
+	}
+	return translatedRow{p.Pool.QueryRow(ctx, sql, args...)}
 }
 
 // Acquire 获取数据库连接
@@ -195,10 +204,19 @@ func (p *postgresPool) Ping(ctx context.Context) error {
 
 // BeginTx starts a new database transaction using the provided context and transaction options.
 // If options are nil, the default transaction ReadCommitted is applied.
+// If ctx carries a transaction started by WithTx, a savepoint of it is returned and opts are ignored.
 // Returns the transaction object or an error if the transaction initialization fails.
 func (p *postgresPool) BeginTx(ctx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
 
-	tx, err := p.Pool.BeginTx(ctx, opts)
+	var (
+		tx  pgx.Tx
+		err error
+	)
+	if outer, ok := txFromContext(ctx, p.Pool); ok {
+		tx, err = outer.Begin(ctx)
+	} else {
+		tx, err = p.Pool.BeginTx(ctx, opts)
+	}
 
 	if err != nil {
 		p.log.Error(nil, "failed to begin transaction", zap.Error(err))
@@ -2,28 +2,24 @@ package db
 
 import (
 	"entgo.io/ent/dialect/sql"
-	"errors"
-	"github.com/jackc/pgx/v5/stdlib"
-	"terraqt.io/colas/bedrock-go/pkg/errs"
 )
 
-func provideDriver(pool PGPool) (*sql.Driver, error) {
-	adp := provideAdaptPool(pool)
-
-	if adp == nil {
-		return nil, errs.WrapCodeError(errs.ErrNotImplemented, errors.New("adp is nil"))
-	}
-
-	db := stdlib.OpenDBFromPool(adp.getStdPool())
-
-	driver := sql.OpenDB("postgres", db)
-
-	return driver, nil
+// provideDriver 返回 database 段对应的 ent 驱动，方言由 DatabaseConfig.Driver 决定
+func provideDriver(dbConfig ConfigGetter, registry *Registry) (*sql.Driver, error) {
+	cfg := dbConfig.GetDbConfig()
+	registry.Register(cfg)
+	return registry.Driver(cfg.Name)
+}
 
+// provideDB 返回 database 段对应的驱动无关连接
+func provideDB(dbConfig ConfigGetter, registry *Registry) (DB, error) {
+	cfg := dbConfig.GetDbConfig()
+	registry.Register(cfg)
+	return registry.DB(cfg.Name)
 }
 
 func provideAdaptPool(pool PGPool) adaptPool {
-	if pool, ok := pool.(*postgresPool); ok {
+	if pool, ok := pool.(adaptPool); ok {
 		return pool
 	}
 	return nil
@@ -0,0 +1,264 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	entsql "entgo.io/ent/dialect/sql"
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/config"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+	"terraqt.io/colas/bedrock-go/pkg/typedsyncmap"
+)
+
+// DatabasesKey 为多数据库配置的顶层 key，每个子 key 为一个数据库名：
+//
+//	databases:
+//	  main:
+//	    host: 127.0.0.1
+//	    database: app
+//	  analytics:
+//	    host: 10.0.0.2
+//	    database: analytics
+//
+// 每个数据库的字段、默认值与校验规则同 database 段，环境变量为 DATABASES_<NAME>_<FIELD>
+const DatabasesKey = "databases"
+
+func init() {
+	config.MapSection[DatabaseConfig](DatabasesKey, databaseOptions)
+}
+
+// databaseOptions 为加载 databases 下名为 name 的数据库时附加的 Option：name 默认取 map 的 key，并应用生产环境规则
+func databaseOptions(name string) []config.Option {
+	return []config.Option{
+		config.WithDefaults(map[string]any{DatabasesKey + "." + name + ".name": name}),
+		productionRule,
+	}
+}
+
+// Registry 按名称管理连接池，首次 Get 时创建，每个名称只创建一次，创建失败的错误同样会被缓存
+type Registry struct {
+	log  logger.Logger
+	opts []config.Option
+
+	// configs 为通过 Register 显式注册的配置，优先于 databases 段
+	configMu sync.RWMutex
+	configs  map[string]DatabaseConfig
+
+	pools typedsyncmap.TypeMapInterface[string, *poolEntry]
+}
+
+// NewRegistry 创建连接池注册表，opts 用于加载 databases 段
+func NewRegistry(log logger.Logger, opts ...config.Option) *Registry {
+	return &Registry{
+		log:     log,
+		opts:    opts,
+		configs: make(map[string]DatabaseConfig),
+		pools:   typedsyncmap.NewTypedSyncMap[string, *poolEntry](),
+	}
+}
+
+var (
+	defaultRegistry     *Registry
+	defaultRegistryOnce sync.Once
+)
+
+// provideRegistry 返回进程内共享的注册表，保证各 injector 拿到的同名连接池是同一个
+func provideRegistry(log logger.Logger) *Registry {
+	defaultRegistryOnce.Do(
+		func() {
+			defaultRegistry = NewRegistry(log)
+		},
+	)
+	return defaultRegistry
+}
+
+// Register 显式注册数据库配置，已注册或已创建连接池的名称不会被覆盖
+func (r *Registry) Register(cfg DatabaseConfig) {
+	r.configMu.Lock()
+	defer r.configMu.Unlock()
+
+	if _, ok := r.configs[cfg.Name]; !ok {
+		r.configs[cfg.Name] = cfg
+	}
+}
+
+// Names 返回显式注册与 databases 段中配置的所有数据库名
+func (r *Registry) Names() ([]string, error) {
+	names, err := config.SubKeys(DatabasesKey, r.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+	for name := range r.configs {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Get 返回名为 name 的 Postgres 连接池，不存在时按配置创建。其他驱动请使用 DB
+func (r *Registry) Get(name string) (PGPool, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	if entry.pool == nil {
+		return nil, errs.WrapCodeError(
+			errs.ErrNotImplemented,
+			fmt.Errorf("database '%s' uses driver '%s', PGPool is only available for postgres", name, entry.driver),
+		)
+	}
+	return entry.pool, nil
+}
+
+// DB 返回名为 name 的驱动无关连接，Postgres 时与 Get 返回的连接池共享连接
+func (r *Registry) DB(name string) (DB, error) {
+	entry, err := r.entry(name)
+	if err != nil {
+		return nil, err
+	}
+	return entry.db, nil
+}
+
+// Driver 返回名为 name 的数据库对应的 ent 驱动，方言由 DatabaseConfig.Driver 决定
+func (r *Registry) Driver(name string) (*entsql.Driver, error) {
+	db, err := r.DB(name)
+	if err != nil {
+		return nil, err
+	}
+	return newEntDriver(db), nil
+}
+
+func (r *Registry) entry(name string) (*poolEntry, error) {
+	entry, _ := r.pools.LoadOrStore(name, &poolEntry{once: &sync.Once{}})
+
+	entry.once.Do(
+		func() {
+			cfg, err := r.config(name)
+			if err != nil {
+				entry.err = err
+				return
+			}
+			entry.name = cfg.Name
+			entry.driver = cfg.Driver
+			entry.pool, entry.db, entry.err = open(cfg, r.log)
+			entry.ready.Store(entry.err == nil)
+		},
+	)
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	return entry, nil
+}
+
+// open 按 DatabaseConfig.Driver 选择连接池的实现
+func open(cfg DatabaseConfig, log logger.Logger) (PGPool, DB, error) {
+	switch cfg.Driver {
+	case DriverPostgres, "":
+		pool, err := newPostgresPool(cfg, log)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(cfg.Replicas) > 0 {
+			pool = newReplicaPool(pool.(*postgresPool), cfg, log)
+		}
+		db, err := newPostgresDB(pool)
+		if err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+		return pool, db, nil
+	case DriverMySQL:
+		db, err := newMySQLDB(cfg, log)
+		return nil, db, err
+	case DriverSQLite:
+		db, err := newSQLiteDB(cfg, log)
+		return nil, db, err
+	default:
+		return nil, nil, errs.WrapCodeError(
+			errs.ErrNotImplemented,
+			fmt.Errorf("unsupported database driver '%s'", cfg.Driver),
+		)
+	}
+}
+
+// config 依次查找显式注册的配置与 databases.<name>
+func (r *Registry) config(name string) (DatabaseConfig, error) {
+	r.configMu.RLock()
+	cfg, ok := r.configs[name]
+	r.configMu.RUnlock()
+	if ok {
+		return cfg, nil
+	}
+
+	key := DatabasesKey + "." + name
+	names, err := config.SubKeys(DatabasesKey, r.opts...)
+	if err != nil {
+		return DatabaseConfig{}, err
+	}
+	if !slices.Contains(names, name) {
+		return DatabaseConfig{}, config.NewErrConfigNotFound(
+			fmt.Errorf("database '%s' is not configured", name),
+			key,
+		)
+	}
+
+	opts := append(databaseOptions(name), r.opts...)
+	return config.Load[DatabaseConfig](config.NewSectionLoader[DatabaseConfig](key), opts...)
+}
+
+// CloseAll 关闭所有已创建的连接池，正在创建的连接池会在创建完成后关闭。
+// pgxpool 的 Close 会等待借出的连接归还，ctx 结束时不再等待，返回 ctx 的错误
+func (r *Registry) CloseAll(ctx context.Context) error {
+	var wg sync.WaitGroup
+	r.pools.Range(
+		func(name string, entry *poolEntry) bool {
+			r.pools.Delete(name)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// 等待正在进行的创建结束；尚未开始的创建不再进行，并发的 Get 会得到错误
+				entry.once.Do(
+					func() {
+						entry.err = errs.WrapCodeError(errs.ErrDBConnection, errors.New("database registry is closed"))
+					},
+				)
+				if !entry.ready.Load() {
+					return
+				}
+				_ = entry.db.Close()
+				if entry.pool != nil {
+					entry.pool.Close()
+				}
+				r.log.Info(ctx, "database connection pool closed", zap.String("name", name))
+			}()
+			return true
+		},
+	)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errs.WrapCodeError(
+			errs.ErrDBConnection,
+			errors.Join(errors.New("close database connection pools timeout"), ctx.Err()),
+		)
+	}
+}
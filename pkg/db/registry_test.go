@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/config"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+// nopLogger 丢弃所有日志
+type nopLogger struct{}
+
+func (nopLogger) Debug(context.Context, string, ...zap.Field) {}
+func (nopLogger) Info(context.Context, string, ...zap.Field)  {}
+func (nopLogger) Warn(context.Context, string, ...zap.Field)  {}
+func (nopLogger) Error(context.Context, string, ...zap.Field) {}
+func (nopLogger) Fatal(context.Context, string, ...zap.Field) {}
+func (nopLogger) Panic(context.Context, string, ...zap.Field) {}
+func (l nopLogger) With(...zap.Field) logger.Logger           { return l }
+func (nopLogger) Sync() error                                 { return nil }
+
+// closeRecorderDB 记录 Close 是否被调用，其余方法未实现
+type closeRecorderDB struct {
+	DB
+	closed atomic.Bool
+}
+
+func (db *closeRecorderDB) Close() error {
+	db.closed.Store(true)
+	return nil
+}
+
+func TestRegistryCachesCreationError(t *testing.T) {
+	r := NewRegistry(nopLogger{}, config.WithConfigPaths(t.TempDir()), config.WithConfigNames("base"))
+
+	_, err := r.Get("late")
+	var notFound config.ErrConfigNotFound
+	if !errors.As(err, &notFound) || notFound.LackConfigName() != DatabasesKey+".late" {
+		t.Fatalf("Get() error = %v, want ErrConfigNotFound of %s.late", err, DatabasesKey)
+	}
+
+	// 创建失败后注册配置也不会重试
+	r.Register(DatabaseConfig{Name: "late", Driver: DriverSQLite, Database: ":memory:"})
+	if _, again := r.Get("late"); again != err {
+		t.Errorf("Get() after a failure = %v, want the cached error %v", again, err)
+	}
+
+	r.Register(DatabaseConfig{Name: "oracle", Driver: "oracle"})
+	_, err = r.DB("oracle")
+	if errCode(err) != errs.ErrNotImplemented.String() {
+		t.Fatalf("DB() error = %v, want ErrNotImplemented", err)
+	}
+	if _, again := r.DB("oracle"); again != err {
+		t.Errorf("DB() after a failure = %v, want the cached error %v", again, err)
+	}
+}
+
+func TestRegistryCloseAllWaitsForCreation(t *testing.T) {
+	r := NewRegistry(nopLogger{})
+
+	// 模拟正在进行的 entry()：创建开始后阻塞，直到 release 关闭
+	entry := &poolEntry{once: &sync.Once{}}
+	r.pools.Store("main", entry)
+	db := &closeRecorderDB{}
+	started, release := make(chan struct{}), make(chan struct{})
+	go entry.once.Do(
+		func() {
+			close(started)
+			<-release
+			entry.db = db
+			entry.ready.Store(true)
+		},
+	)
+	<-started
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- r.CloseAll(context.Background())
+	}()
+
+	select {
+	case err := <-closed:
+		t.Fatalf("CloseAll() = %v before the creation finished", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("CloseAll() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CloseAll() did not return after the creation finished")
+	}
+	if !db.closed.Load() {
+		t.Error("CloseAll() did not close the pool created concurrently")
+	}
+	if _, ok := r.pools.Load("main"); ok {
+		t.Error("CloseAll() kept the closed pool in the registry")
+	}
+}
+
+func TestRegistryCloseAllBeforeCreation(t *testing.T) {
+	r := NewRegistry(nopLogger{})
+
+	// 已取得 entry 但尚未开始创建的 Get 在 CloseAll 后得到错误，不再创建
+	entry := &poolEntry{once: &sync.Once{}}
+	r.pools.Store("main", entry)
+	if err := r.CloseAll(context.Background()); err != nil {
+		t.Fatalf("CloseAll() error = %v", err)
+	}
+
+	created := false
+	entry.once.Do(
+		func() {
+			created = true
+		},
+	)
+	if created {
+		t.Error("pool created after CloseAll()")
+	}
+	if errCode(entry.err) != errs.ErrDBConnection.String() {
+		t.Errorf("entry error = %v, want ErrDBConnection", entry.err)
+	}
+}
+
+func TestRegistryCloseAllTimeout(t *testing.T) {
+	r := NewRegistry(nopLogger{})
+
+	entry := &poolEntry{once: &sync.Once{}}
+	r.pools.Store("main", entry)
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	go entry.once.Do(
+		func() {
+			close(started)
+			<-release
+		},
+	)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := r.CloseAll(ctx); errCode(err) != errs.ErrDBConnection.String() || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CloseAll() error = %v, want ErrDBConnection wrapping the ctx error", err)
+	}
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+// replicaCheckTimeout 为单次副本健康检查的超时
+const replicaCheckTimeout = 3 * time.Second
+
+// replicaLagSQL 查询副本的复制延迟（秒），已回放全部接收到的 WAL 时视为无延迟，
+// 避免主库空闲时 pg_last_xact_replay_timestamp 不再更新导致误判
+const replicaLagSQL = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+
+type primaryKey struct{}
+
+// WithPrimary 返回强制使用主库的 ctx，用于写后立即读等不能容忍复制延迟的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func usePrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// 副本的健康状态，首次检查完成前为 replicaUnknown，不承接读请求
+const (
+	replicaUnknown int32 = iota
+	replicaHealthy
+	replicaUnhealthy
+)
+
+// replica 为一个只读副本，pool 在首次连接成功后创建，state 由健康检查与查询失败更新
+type replica struct {
+	cfg   DatabaseConfig
+	pool  atomic.Pointer[postgresPool]
+	state atomic.Int32
+}
+
+func (r *replica) healthy() bool {
+	return r.state.Load() == replicaHealthy
+}
+
+func (r *replica) addr() string {
+	return net.JoinHostPort(r.cfg.Host, strconv.Itoa(int(r.cfg.Port)))
+}
+
+// replicaPool 将事务外的 Query / QueryRow 轮询路由到健康的只读副本，
+// Exec、BeginTx、Acquire、事务中的读以及 WithPrimary 的 ctx 均使用主库。
+// 副本复制延迟超过 ReplicaMaxLag、健康检查失败或查询时连接中断时，读请求回退到主库
+type replicaPool struct {
+	*postgresPool
+	replicas []*replica
+	next     atomic.Uint64
+	maxLag   time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func newReplicaPool(primary *postgresPool, cfg DatabaseConfig, log logger.Logger) *replicaPool {
+	p := &replicaPool{
+		postgresPool: primary,
+		maxLag:       cfg.ReplicaMaxLag,
+		done:         make(chan struct{}),
+	}
+	for _, host := range cfg.Replicas {
+		rcfg := cfg
+		rcfg.Replicas = nil
+		rcfg.Host, rcfg.Port = splitReplicaHost(host, cfg.Port)
+		p.replicas = append(p.replicas, &replica{cfg: rcfg})
+	}
+
+	// 健康检查在后台进行，首次检查完成前读请求使用主库，不可达的副本不会拖慢启动
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+
+	interval := cfg.ReplicaCheckInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	go p.checkLoop(ctx, interval)
+
+	log.Info(nil, "database replica routing is enabled", zap.String("name", cfg.Name), zap.Strings("replicas", cfg.Replicas))
+	return p
+}
+
+// splitReplicaHost 解析 host 或 host:port，未指定端口时使用主库端口
+func splitReplicaHost(host string, defaultPort int32) (string, int32) {
+	h, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host, defaultPort
+	}
+	n, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return h, defaultPort
+	}
+	return h, int32(n)
+}
+
+func (p *replicaPool) checkLoop(ctx context.Context, interval time.Duration) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	p.checkReplicas(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.checkReplicas(ctx)
+		}
+	}
+}
+
+func (p *replicaPool) checkReplicas(ctx context.Context) {
+	for _, r := range p.replicas {
+		err := p.check(ctx, r)
+		// Close 取消检查时不更新状态，避免关闭过程中记录副本不可用
+		if ctx.Err() != nil {
+			return
+		}
+		p.setHealthy(ctx, r, err)
+	}
+}
+
+// check 连接副本并检查复制延迟，返回 nil 表示副本可以承接读请求。
+// 连接与查询共用 replicaCheckTimeout，Close 取消 ctx 后立即返回
+func (p *replicaPool) check(ctx context.Context, r *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	pool := r.pool.Load()
+	if pool == nil {
+		created, err := connectPostgres(ctx, r.cfg, p.log)
+		if err != nil {
+			return err
+		}
+		pool = created
+		r.pool.Store(pool)
+	}
+
+	var seconds float64
+	if err := pool.Pool.QueryRow(ctx, replicaLagSQL).Scan(&seconds); err != nil {
+		return fmt.Errorf("check replica lag error: %w", err)
+	}
+	if lag := time.Duration(seconds * float64(time.Second)); p.maxLag > 0 && lag > p.maxLag {
+		return fmt.Errorf("replication lag %s exceeds %s", lag.Round(time.Millisecond), p.maxLag)
+	}
+	return nil
+}
+
+// setHealthy 更新副本状态，是副本状态唯一的日志来源，仅在状态变化时记录
+func (p *replicaPool) setHealthy(ctx context.Context, r *replica, err error) {
+	state := replicaHealthy
+	if err != nil {
+		state = replicaUnhealthy
+	}
+	if r.state.Swap(state) == state {
+		return
+	}
+	if err == nil {
+		p.log.Info(ctx, "database replica is healthy, routing reads to it", zap.String("replica", r.addr()))
+		return
+	}
+	p.log.Warn(ctx, "database replica is unhealthy, reads fall back to primary", zap.String("replica", r.addr()), zap.Error(err))
+}
+
+// pick 轮询选择健康的副本，需要使用主库时返回 nil
+func (p *replicaPool) pick(ctx context.Context) (*replica, *postgresPool) {
+	if usePrimary(ctx) {
+		return nil, nil
+	}
+	if _, ok := txFromContext(ctx, p.Pool); ok {
+		return nil, nil
+	}
+
+	n := uint64(len(p.replicas))
+	start := p.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := p.replicas[(start+i)%n]
+		if !r.healthy() {
+			continue
+		}
+		if pool := r.pool.Load(); pool != nil {
+			return r, pool
+		}
+	}
+	return nil, nil
+}
+
+// fallback 判断副本上的错误是否应回退到主库，连接中断时同时将副本标记为不健康，等待下次健康检查恢复
+func (p *replicaPool) fallback(ctx context.Context, r *replica, err error) bool {
+	if ctx.Err() != nil || !isConnectionLost(err) {
+		return false
+	}
+	p.setHealthy(ctx, r, err)
+	return true
+}
+
+// Query 事务外的查询优先使用副本，副本连接中断时改用主库
+func (p *replicaPool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	r, pool := p.pick(ctx)
+	if pool == nil {
+		return p.postgresPool.Query(ctx, sql, args...)
+	}
+
+	rows, err := pool.Query(ctx, sql, args...)
+	if err != nil && p.fallback(ctx, r, err) {
+		return p.postgresPool.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+// QueryRow 同 Query，错误在 Scan 时才返回，因此回退也发生在 Scan 中
+func (p *replicaPool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	r, pool := p.pick(ctx)
+	if pool == nil {
+		return p.postgresPool.QueryRow(ctx, sql, args...)
+	}
+
+	return fallbackRow{
+		Row: pool.QueryRow(ctx, sql, args...),
+		fallback: func(err error) pgx.Row {
+			if !p.fallback(ctx, r, err) {
+				return nil
+			}
+			return p.postgresPool.QueryRow(ctx, sql, args...)
+		},
+	}
+}
+
+// Close 停止健康检查并关闭所有副本与主库的连接池
+func (p *replicaPool) Close() {
+	p.cancel()
+	<-p.done
+	for _, r := range p.replicas {
+		if pool := r.pool.Load(); pool != nil {
+			pool.Close()
+		}
+	}
+	p.postgresPool.Close()
+}
+
+type fallbackRow struct {
+	pgx.Row
+	fallback func(err error) pgx.Row
+}
+
+func (r fallbackRow) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	if err == nil {
+		return nil
+	}
+	if row := r.fallback(err); row != nil {
+		return row.Scan(dest...)
+	}
+	return err
+}
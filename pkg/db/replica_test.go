@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// newTestReplicaPool 创建不连接数据库、不做健康检查的 replicaPool，states 为各副本的状态
+func newTestReplicaPool(states ...int32) *replicaPool {
+	p := &replicaPool{postgresPool: &postgresPool{log: nopLogger{}}}
+	for _, state := range states {
+		r := &replica{}
+		r.pool.Store(&postgresPool{})
+		r.state.Store(state)
+		p.replicas = append(p.replicas, r)
+	}
+	return p
+}
+
+func TestReplicaPoolPick(t *testing.T) {
+	p := newTestReplicaPool(replicaHealthy, replicaUnhealthy, replicaUnknown, replicaHealthy)
+	// 状态健康但尚未建立连接池的副本同样跳过
+	notConnected := &replica{}
+	notConnected.state.Store(replicaHealthy)
+	p.replicas = append(p.replicas, notConnected)
+
+	// 只在健康且已连接的副本间轮询
+	picked := make(map[*replica]int)
+	for range 10 {
+		r, pool := p.pick(context.Background())
+		if r == nil || pool != r.pool.Load() {
+			t.Fatalf("pick() = %v, %v, want a healthy replica", r, pool)
+		}
+		picked[r]++
+	}
+	if len(picked) != 2 || picked[p.replicas[0]] == 0 || picked[p.replicas[3]] == 0 {
+		t.Errorf("pick() distribution = %v, want only replicas 0 and 3", picked)
+	}
+
+	none := newTestReplicaPool(replicaUnhealthy, replicaUnknown)
+	if r, pool := none.pick(context.Background()); r != nil || pool != nil {
+		t.Errorf("pick() without healthy replicas = %v, %v, want primary", r, pool)
+	}
+}
+
+func TestReplicaPoolPickPrimary(t *testing.T) {
+	p := newTestReplicaPool(replicaHealthy)
+
+	if r, pool := p.pick(WithPrimary(context.Background())); r != nil || pool != nil {
+		t.Errorf("pick(WithPrimary) = %v, %v, want primary", r, pool)
+	}
+
+	// WithPoolTx 开启的事务中的读使用主库
+	var events []string
+	txPool := &fakeTxPool{
+		postgresPool: p.postgresPool,
+		begin: func() *fakeTx {
+			return &fakeTx{name: "tx", events: &events}
+		},
+	}
+	err := WithPoolTx(
+		context.Background(), txPool, pgx.TxOptions{}, func(ctx context.Context) error {
+			if r, pool := p.pick(ctx); r != nil || pool != nil {
+				t.Errorf("pick() in a transaction = %v, %v, want primary", r, pool)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		t.Fatalf("WithPoolTx() error = %v", err)
+	}
+
+	if r, _ := p.pick(context.Background()); r == nil {
+		t.Error("pick() after the transaction = nil, want the replica")
+	}
+}
+
+func TestSplitReplicaHost(t *testing.T) {
+	tests := []struct {
+		host     string
+		wantHost string
+		wantPort int32
+	}{
+		{"replica-1", "replica-1", 5432},
+		{"replica-1:6432", "replica-1", 6432},
+		{"[::1]:6432", "::1", 6432},
+		{"replica-1:port", "replica-1", 5432},
+	}
+	for _, tt := range tests {
+		host, port := splitReplicaHost(tt.host, 5432)
+		if host != tt.wantHost || port != tt.wantPort {
+			t.Errorf("splitReplicaHost(%q) = %q, %d, want %q, %d", tt.host, host, port, tt.wantHost, tt.wantPort)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+	"terraqt.io/colas/bedrock-go/pkg/typedsyncmap"
+)
+
+// 慢查询日志中参数的输出方式
+const (
+	SlowQueryArgsNone  = "none"  // 不输出参数，只输出归一化后的 SQL
+	SlowQueryArgsMask  = "mask"  // 只输出参数类型与长度与归一化后的 SQL
+	SlowQueryArgsPlain = "plain" // 输出参数原值与原始 SQL，过长的字符串会被截断
+)
+
+const (
+	// queryStatsWindow 为 p50 / p99 的统计窗口
+	queryStatsWindow = 5 * time.Minute
+	// queryStatsSamples 为每个 fingerprint 保留的最近耗时样本数
+	queryStatsSamples = 1024
+	// queryStatsMaxFingerprints 为每个数据库最多统计的 fingerprint 数，超出时淘汰最久未执行的
+	queryStatsMaxFingerprints = 1000
+	// maxPlainArgLen 为 plain 模式下单个字符串参数的最大输出长度
+	maxPlainArgLen = 256
+)
+
+// slowQueryLayer 为每条 SQL 累计 fingerprint 统计，耗时超过阈值时以 Warn 级别输出，与 DebugSQL 无关
+type slowQueryLayer struct {
+	log       logger.Logger
+	database  string
+	threshold time.Duration
+	argsMode  string
+	stats     *queryStats
+}
+
+func newSlowQueryLayer(cfg DatabaseConfig, log logger.Logger) *slowQueryLayer {
+	stats, _ := queryStatsByDB.LoadOrStore(cfg.Name, newQueryStats())
+	return &slowQueryLayer{
+		log:       log,
+		database:  cfg.Name,
+		threshold: cfg.SlowQueryThreshold,
+		argsMode:  cfg.SlowQueryArgs,
+		stats:     stats,
+	}
+}
+
+func (s *slowQueryLayer) queryStart(context.Context, *queryTrace) {}
+
+func (s *slowQueryLayer) queryEnd(ctx context.Context, q *queryTrace, tag pgconn.CommandTag, err error) {
+	duration := time.Since(q.start)
+	normalized := normalizeSQL(q.sql)
+	id := fingerprintID(normalized)
+	slow := s.threshold > 0 && duration >= s.threshold
+
+	s.stats.record(id, normalized, duration, slow)
+
+	if !slow {
+		return
+	}
+	fields := []zap.Field{
+		zap.String("database", s.database),
+		zap.Duration("duration", duration),
+		zap.Duration("threshold", s.threshold),
+		zap.Int64("rows_affected", tag.RowsAffected()),
+		zap.String("fingerprint", id),
+		zap.String("query", normalized),
+	}
+	// 原始 SQL 中可能内联了字面量，只在 plain 模式下输出
+	if s.argsMode == SlowQueryArgsPlain {
+		fields = append(fields, zap.String("sql", q.sql))
+	}
+	if args := redactArgs(q.args, s.argsMode); args != nil {
+		fields = append(fields, zap.Any("args", args))
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	s.log.Warn(ctx, "slow SQL query", fields...)
+}
+
+// redactArgs 按 mode 处理参数，none 时返回 nil
+func redactArgs(args []any, mode string) []any {
+	if mode == SlowQueryArgsNone || len(args) == 0 {
+		return nil
+	}
+
+	result := make([]any, len(args))
+	for i, arg := range args {
+		if mode == SlowQueryArgsPlain {
+			result[i] = plainArg(arg)
+		} else {
+			result[i] = maskArg(arg)
+		}
+	}
+	return result
+}
+
+func maskArg(arg any) string {
+	switch v := arg.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("string(%d)", len(v))
+	case []byte:
+		return fmt.Sprintf("bytes(%d)", len(v))
+	default:
+		return fmt.Sprintf("%T", arg)
+	}
+}
+
+func plainArg(arg any) any {
+	switch v := arg.(type) {
+	case string:
+		if len(v) > maxPlainArgLen {
+			return v[:maxPlainArgLen] + "..."
+		}
+		return v
+	case []byte:
+		return fmt.Sprintf("bytes(%d)", len(v))
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return arg
+	}
+}
+
+// inListPattern 匹配规范化后的 (?, ?, ...) 列表
+var inListPattern = regexp.MustCompile(`\(\?(?:, \?)+\)`)
+
+// normalizeSQL 将 SQL 规范化为 fingerprint：去掉注释，字面量与占位符替换为 ?，
+// 多个值的列表合并为 (?+)，空白合并为单个空格，双引号外的部分转为小写
+func normalizeSQL(sql string) string {
+	var b strings.Builder
+	b.Grow(len(sql))
+
+	// space 表示下一个 token 前需要空格，左括号之后与逗号、右括号之前不加空格
+	space := false
+	emit := func(s string) {
+		if space && b.Len() > 0 {
+			last := b.String()[b.Len()-1]
+			if last != '(' && s[0] != ',' && s[0] != ')' {
+				b.WriteByte(' ')
+			}
+		}
+		space = false
+		b.WriteString(s)
+	}
+
+	runes := []rune(sql)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			space = true
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			for i += 2; i+1 < len(runes) && (runes[i] != '*' || runes[i+1] != '/'); i++ {
+			}
+			i++
+			space = true
+
+		case r == '\'':
+			// '' 为转义的单引号
+			for i++; i < len(runes); i++ {
+				if runes[i] != '\'' {
+					continue
+				}
+				if i+1 < len(runes) && runes[i+1] == '\'' {
+					i++
+					continue
+				}
+				break
+			}
+			emit("?")
+
+		case r == '"':
+			start := i
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+			}
+			emit(string(runes[start:min(i+1, len(runes))]))
+
+		case r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			for i+1 < len(runes) && unicode.IsDigit(runes[i+1]) {
+				i++
+			}
+			emit("?")
+
+		case unicode.IsDigit(r) && (i == 0 || !isIdentRune(runes[i-1])):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.') {
+				i++
+			}
+			emit("?")
+
+		default:
+			emit(string(unicode.ToLower(r)))
+			if r == ',' {
+				space = true
+			}
+		}
+	}
+
+	normalized := strings.TrimSuffix(b.String(), ";")
+	return inListPattern.ReplaceAllString(normalized, "(?+)")
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// fingerprintID 返回规范化 SQL 的短哈希，便于在日志中检索同一类查询
+func fingerprintID(normalized string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(normalized))
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// queryStatsByDB 按 DatabaseConfig.Name 保存各数据库的 fingerprint 统计
+var queryStatsByDB = typedsyncmap.NewTypedSyncMap[string, *queryStats]()
+
+type querySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// fingerprintStats 为单个 fingerprint 的累计次数与最近的耗时样本（环形缓冲）
+type fingerprintStats struct {
+	query    string
+	total    int64
+	slow     int64
+	lastSeen time.Time
+	samples  []querySample
+	next     int
+}
+
+type queryStats struct {
+	mu    sync.Mutex
+	items map[string]*fingerprintStats
+}
+
+func newQueryStats() *queryStats {
+	return &queryStats{items: make(map[string]*fingerprintStats)}
+}
+
+func (s *queryStats) record(id, query string, duration time.Duration, slow bool) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		if len(s.items) >= queryStatsMaxFingerprints {
+			s.evictLocked()
+		}
+		item = &fingerprintStats{query: query}
+		s.items[id] = item
+	}
+
+	item.total++
+	if slow {
+		item.slow++
+	}
+	item.lastSeen = now
+
+	sample := querySample{at: now, duration: duration}
+	if len(item.samples) < queryStatsSamples {
+		item.samples = append(item.samples, sample)
+	} else {
+		item.samples[item.next] = sample
+		item.next = (item.next + 1) % queryStatsSamples
+	}
+}
+
+// evictLocked 淘汰最久未执行的 fingerprint
+func (s *queryStats) evictLocked() {
+	var (
+		oldest string
+		at     time.Time
+	)
+	for id, item := range s.items {
+		if oldest == "" || item.lastSeen.Before(at) {
+			oldest, at = id, item.lastSeen
+		}
+	}
+	delete(s.items, oldest)
+}
+
+// QueryStat 为某个 fingerprint 的统计，Count、P50、P99、Max 只统计最近窗口内（至多 1024 个样本），
+// Total 与 Slow 为进程启动以来的累计值
+type QueryStat struct {
+	Database    string    `json:"database"`
+	Fingerprint string    `json:"fingerprint"`
+	Query       string    `json:"query"`
+	Count       int       `json:"count"`
+	Total       int64     `json:"total"`
+	Slow        int64     `json:"slow"`
+	P50Ms       float64   `json:"p50_ms"`
+	P99Ms       float64   `json:"p99_ms"`
+	MaxMs       float64   `json:"max_ms"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+func (s *queryStats) snapshot(database string) []QueryStat {
+	since := time.Now().Add(-queryStatsWindow)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]QueryStat, 0, len(s.items))
+	for id, item := range s.items {
+		durations := make([]time.Duration, 0, len(item.samples))
+		for _, sample := range item.samples {
+			if sample.at.After(since) {
+				durations = append(durations, sample.duration)
+			}
+		}
+		slices.Sort(durations)
+
+		stat := QueryStat{
+			Database:    database,
+			Fingerprint: id,
+			Query:       item.query,
+			Count:       len(durations),
+			Total:       item.total,
+			Slow:        item.slow,
+			LastSeen:    item.lastSeen,
+		}
+		if len(durations) > 0 {
+			stat.P50Ms = milliseconds(percentile(durations, 0.50))
+			stat.P99Ms = milliseconds(percentile(durations, 0.99))
+			stat.MaxMs = milliseconds(durations[len(durations)-1])
+		}
+		result = append(result, stat)
+	}
+	return result
+}
+
+// percentile 使用 nearest-rank 计算分位数，sorted 需已升序排列
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(float64(len(sorted))*p+0.999999) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// QueryStats 返回所有数据库的 fingerprint 统计，按窗口内执行次数降序排列
+func QueryStats() []QueryStat {
+	var result []QueryStat
+	queryStatsByDB.Range(
+		func(name string, stats *queryStats) bool {
+			result = append(result, stats.snapshot(name)...)
+			return true
+		},
+	)
+	sortQueryStats(result, "count")
+	return result
+}
+
+func sortQueryStats(stats []QueryStat, by string) {
+	slices.SortFunc(
+		stats, func(a, b QueryStat) int {
+			var c int
+			switch by {
+			case "p50":
+				c = compareFloat(b.P50Ms, a.P50Ms)
+			case "p99":
+				c = compareFloat(b.P99Ms, a.P99Ms)
+			case "max":
+				c = compareFloat(b.MaxMs, a.MaxMs)
+			case "total":
+				c = int(b.Total - a.Total)
+			case "slow":
+				c = int(b.Slow - a.Slow)
+			default:
+				c = b.Count - a.Count
+			}
+			if c != 0 {
+				return c
+			}
+			return strings.Compare(a.Fingerprint, b.Fingerprint)
+		},
+	)
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// QueryStatsHandler 返回查看 SQL fingerprint 统计的 http.Handler，可挂载到管理端口：
+//
+//	?db=     只看某个数据库（DatabaseConfig.Name）
+//	?sort=   count（默认）、total、slow、p50、p99、max，均为降序
+//	?limit=  最多返回的条数，默认 50
+func QueryStatsHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				writeStatsJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+				return
+			}
+
+			query := r.URL.Query()
+			limit := 50
+			if v := query.Get("limit"); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil || n <= 0 {
+					writeStatsJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid limit '" + v + "'"})
+					return
+				}
+				limit = n
+			}
+
+			var stats []QueryStat
+			if name := query.Get("db"); name != "" {
+				if s, ok := queryStatsByDB.Load(name); ok {
+					stats = s.snapshot(name)
+				}
+			} else {
+				stats = QueryStats()
+			}
+			if stats == nil {
+				stats = []QueryStat{}
+			}
+
+			sortQueryStats(stats, query.Get("sort"))
+			if len(stats) > limit {
+				stats = stats[:limit]
+			}
+			writeStatsJSON(w, http.StatusOK, stats)
+		},
+	)
+}
+
+func writeStatsJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
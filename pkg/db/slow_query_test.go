@@ -0,0 +1,105 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{
+			sql:  "SELECT * FROM users WHERE id = $1",
+			want: "select * from users where id = ?",
+		},
+		{
+			sql:  "select  *\n\tfrom users\nwhere name = 'O''Brien' and age > 30;",
+			want: "select * from users where name = ? and age > ?",
+		},
+		{
+			sql:  "SELECT id FROM t WHERE id IN (1, 2, 3)",
+			want: "select id from t where id in (?+)",
+		},
+		{
+			sql:  "SELECT id FROM t WHERE id IN ($1,$2)",
+			want: "select id from t where id in (?+)",
+		},
+		{
+			sql:  "INSERT INTO t (a, b) VALUES ($1, $2)",
+			want: "insert into t (a, b) values (?+)",
+		},
+		{
+			sql:  "SELECT price * 1.5 FROM t2 -- comment\nWHERE x = 1",
+			want: "select price * ? from t2 where x = ?",
+		},
+		{
+			sql:  "SELECT /* hint */ \"UserName\" FROM \"Users\"",
+			want: "select \"UserName\" from \"Users\"",
+		},
+		{
+			sql:  "SELECT count(*) FROM t WHERE id = $10",
+			want: "select count(*) from t where id = ?",
+		},
+	}
+	for _, tt := range tests {
+		if got := normalizeSQL(tt.sql); got != tt.want {
+			t.Errorf("normalizeSQL(%q) = %q, want %q", tt.sql, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeSQLFingerprint(t *testing.T) {
+	a := fingerprintID(normalizeSQL("SELECT * FROM users WHERE id = 1"))
+	b := fingerprintID(normalizeSQL("select *\nfrom users where id = $1"))
+	if a != b {
+		t.Errorf("fingerprints differ for the same query shape: %s != %s", a, b)
+	}
+
+	c := fingerprintID(normalizeSQL("SELECT * FROM orders WHERE id = 1"))
+	if a == c {
+		t.Errorf("fingerprints equal for different tables: %s", a)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := make([]time.Duration, 100)
+	for i := range sorted {
+		sorted[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	tests := []struct {
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{sorted, 0.5, 50 * time.Millisecond},
+		{sorted, 0.99, 99 * time.Millisecond},
+		{sorted, 1, 100 * time.Millisecond},
+		{sorted, 0, time.Millisecond},
+		{sorted[:1], 0.5, time.Millisecond},
+		{sorted[:1], 0.99, time.Millisecond},
+		{sorted[:3], 0.5, 2 * time.Millisecond},
+		{sorted[:3], 0.99, 3 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(len=%d, %v) = %v, want %v", len(tt.sorted), tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := []any{nil, "secret", []byte("abc"), 42}
+
+	if got := redactArgs(args, SlowQueryArgsNone); got != nil {
+		t.Errorf("redactArgs(none) = %v, want nil", got)
+	}
+
+	want := []any{"NULL", "string(6)", "bytes(3)", "int"}
+	if got := redactArgs(args, SlowQueryArgsMask); !reflect.DeepEqual(got, want) {
+		t.Errorf("redactArgs(mask) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+// 支持的 DatabaseConfig.Driver
+const (
+	DriverPostgres = "postgres"
+	DriverMySQL    = "mysql"
+	DriverSQLite   = "sqlite"
+)
+
+// Executor 是 *sql.DB 与 *sql.Tx 共有的读写方法，业务代码依赖它即可在事务内外复用
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// DB 是与驱动无关的数据库接口，基于 database/sql，Postgres、MySQL、SQLite 均可使用。
+// 需要 pgx 专有能力（如 CopyFrom、批量）时使用 PGPool
+type DB interface {
+	Executor
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	PingContext(ctx context.Context) error
+	Close() error
+	// Dialect 返回 ent 使用的方言名
+	Dialect() string
+	// SQLDB 返回底层的 *sql.DB
+	SQLDB() *sql.DB
+}
+
+type sqlDB struct {
+	*sql.DB
+	dialect string
+}
+
+func (d *sqlDB) Dialect() string {
+	return d.dialect
+}
+
+func (d *sqlDB) SQLDB() *sql.DB {
+	return d.DB
+}
+
+// newPostgresDB 基于已有的 pgx 连接池创建 *sql.DB，两者共享连接
+func newPostgresDB(pool PGPool) (DB, error) {
+	adp := provideAdaptPool(pool)
+	if adp == nil {
+		return nil, errs.WrapCodeError(errs.ErrNotImplemented, errors.New("adp is nil"))
+	}
+	return &sqlDB{DB: stdlib.OpenDBFromPool(adp.getStdPool()), dialect: dialect.Postgres}, nil
+}
+
+// openSQLDB 打开 database/sql 连接并按配置设置连接池参数，driverName 对应的驱动需由应用引入
+func openSQLDB(cfg DatabaseConfig, driverName string, dsn string, dialectName string, log logger.Logger) (DB, error) {
+	if !slices.Contains(sql.Drivers(), driverName) {
+		return nil, errs.WrapCodeError(
+			errs.ErrResourceInitFailed,
+			fmt.Errorf("sql driver '%s' is not registered, import it in the main package", driverName),
+		)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		log.Error(nil, "failed to open database", zap.String("driver", cfg.Driver), zap.Error(err))
+		return nil, errs.WrapCodeError(
+			errs.ErrInvalidParam,
+			fmt.Errorf("failed to open %s database: %w", cfg.Driver, err),
+		)
+	}
+
+	db.SetMaxOpenConns(int(cfg.MaxOpenConns))
+	db.SetMaxIdleConns(int(cfg.MaxIdleConns))
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		log.Error(nil, "failed to ping database", zap.String("driver", cfg.Driver), zap.Error(err))
+		_ = db.Close()
+		return nil, errs.WrapCodeError(
+			errs.ErrDBConnection,
+			fmt.Errorf("failed to ping %s database: %w", cfg.Driver, err),
+		)
+	}
+
+	log.Info(nil, "Successfully connected to database", zap.String("driver", cfg.Driver))
+
+	return &sqlDB{DB: db, dialect: dialectName}, nil
+}
+
+// newEntDriver 返回 ent 使用的 *sql.Driver
+func newEntDriver(db DB) *entsql.Driver {
+	return entsql.OpenDB(db.Dialect(), db.SQLDB())
+}
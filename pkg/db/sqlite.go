@@ -0,0 +1,40 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+
+	"entgo.io/ent/dialect"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+// newSQLiteDB 创建 SQLite 连接池，database 为文件路径或 :memory:，适用于本地快速测试。
+// 需在 main 包中引入 github.com/mattn/go-sqlite3（cgo）或 modernc.org/sqlite（纯 Go）之一
+func newSQLiteDB(cfg DatabaseConfig, log logger.Logger) (DB, error) {
+	driverName := ""
+	for _, name := range []string{"sqlite3", "sqlite"} {
+		if slices.Contains(sql.Drivers(), name) {
+			driverName = name
+			break
+		}
+	}
+	if driverName == "" {
+		return nil, errs.WrapCodeError(
+			errs.ErrResourceInitFailed,
+			errors.New("no sqlite driver registered, import github.com/mattn/go-sqlite3 or modernc.org/sqlite"),
+		)
+	}
+
+	// 内存库的每个连接都是独立的数据库，只能使用一个连接
+	if strings.Contains(cfg.Database, ":memory:") || strings.Contains(cfg.Database, "mode=memory") {
+		cfg.MaxOpenConns = 1
+		cfg.MaxIdleConns = 1
+		cfg.ConnMaxLifetime = 0
+		cfg.ConnMaxIdleTime = 0
+	}
+
+	return openSQLDB(cfg, driverName, cfg.Database, dialect.SQLite, log)
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// PoolStats 为连接池 pgxpool.Stat 的快照，Count 结尾的字段为连接池创建以来的累计值
+type PoolStats struct {
+	Name                    string  `json:"name"`
+	AcquireCount            int64   `json:"acquire_count"`
+	AcquireDurationMs       float64 `json:"acquire_duration_ms"` // 累计等待获取连接的时间
+	EmptyAcquireCount       int64   `json:"empty_acquire_count"` // 没有空闲连接、需要等待或新建连接的次数
+	CanceledAcquireCount    int64   `json:"canceled_acquire_count"`
+	AcquiredConns           int32   `json:"acquired_conns"`
+	IdleConns               int32   `json:"idle_conns"`
+	ConstructingConns       int32   `json:"constructing_conns"`
+	TotalConns              int32   `json:"total_conns"`
+	MaxConns                int32   `json:"max_conns"`
+	NewConnsCount           int64   `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64   `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64   `json:"max_idle_destroy_count"`
+}
+
+func newPoolStats(name string, s *pgxpool.Stat) PoolStats {
+	return PoolStats{
+		Name:                    name,
+		AcquireCount:            s.AcquireCount(),
+		AcquireDurationMs:       milliseconds(s.AcquireDuration()),
+		EmptyAcquireCount:       s.EmptyAcquireCount(),
+		CanceledAcquireCount:    s.CanceledAcquireCount(),
+		AcquiredConns:           s.AcquiredConns(),
+		IdleConns:               s.IdleConns(),
+		ConstructingConns:       s.ConstructingConns(),
+		TotalConns:              s.TotalConns(),
+		MaxConns:                s.MaxConns(),
+		NewConnsCount:           s.NewConnsCount(),
+		MaxLifetimeDestroyCount: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     s.MaxIdleDestroyCount(),
+	}
+}
+
+// rangePools 遍历已成功创建的 Postgres 连接池，不会触发连接池的创建。
+// 只读副本的连接池名称为 <name>@<host>:<port>
+func (r *Registry) rangePools(fn func(name string, pool *pgxpool.Pool)) {
+	r.pools.Range(
+		func(_ string, entry *poolEntry) bool {
+			if !entry.ready.Load() {
+				return true
+			}
+			if p, ok := entry.pool.(adaptPool); ok {
+				fn(entry.name, p.getStdPool())
+			}
+			if p, ok := entry.pool.(*replicaPool); ok {
+				for _, r := range p.replicas {
+					if pool := r.pool.Load(); pool != nil {
+						fn(entry.name+"@"+r.addr(), pool.Pool)
+					}
+				}
+			}
+			return true
+		},
+	)
+}
+
+// Stats 返回已创建的 Postgres 连接池的统计，按 DatabaseConfig.Name 排序
+func (r *Registry) Stats() []PoolStats {
+	stats := make([]PoolStats, 0)
+	r.rangePools(
+		func(name string, pool *pgxpool.Pool) {
+			stats = append(stats, newPoolStats(name, pool.Stat()))
+		},
+	)
+	slices.SortFunc(
+		stats, func(a, b PoolStats) int {
+			return strings.Compare(a.Name, b.Name)
+		},
+	)
+	return stats
+}
+
+// StatsHandler 返回输出连接池统计的 http.Handler，可挂载到管理端口，?name= 只看某个数据库
+func (r *Registry) StatsHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			if req.Method != http.MethodGet {
+				writeStatsJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+				return
+			}
+
+			stats := r.Stats()
+			if name := req.URL.Query().Get("name"); name != "" {
+				stats = slices.DeleteFunc(
+					stats, func(s PoolStats) bool {
+						return s.Name != name
+					},
+				)
+			}
+			writeStatsJSON(w, http.StatusOK, stats)
+		},
+	)
+}
+
+// RegisterMetrics 将各连接池的统计注册为 mp 上的异步指标，以 pool.name（DatabaseConfig.Name）区分连接池，
+// 之后创建的连接池同样会被采集。返回的 Registration 用于取消注册
+func (r *Registry) RegisterMetrics(mp metric.MeterProvider) (metric.Registration, error) {
+	meter := mp.Meter(tracerName)
+
+	usage, err := meter.Int64ObservableUpDownCounter(
+		"db.client.connections.usage",
+		metric.WithDescription("The number of connections that are currently in state described by the state attribute."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	maxConns, err := meter.Int64ObservableUpDownCounter(
+		"db.client.connections.max",
+		metric.WithDescription("The maximum number of open connections allowed."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	acquires, err := meter.Int64ObservableCounter(
+		"db.client.connections.acquires",
+		metric.WithDescription("The number of successful connection acquires, by whether an idle connection was available."),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	canceled, err := meter.Int64ObservableCounter(
+		"db.client.connections.canceled_acquires",
+		metric.WithDescription("The number of acquires canceled by the caller's context."),
+		metric.WithUnit("{acquire}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	waitTime, err := meter.Float64ObservableCounter(
+		"db.client.connections.acquire_time",
+		metric.WithDescription("The total time spent waiting to acquire a connection."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	created, err := meter.Int64ObservableCounter(
+		"db.client.connections.created",
+		metric.WithDescription("The number of connections opened."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	destroyed, err := meter.Int64ObservableCounter(
+		"db.client.connections.destroyed",
+		metric.WithDescription("The number of connections closed for exceeding max lifetime or max idle time."),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(
+		func(_ context.Context, o metric.Observer) error {
+			r.rangePools(
+				func(name string, pool *pgxpool.Pool) {
+					s := pool.Stat()
+					poolName := semconv.PoolName(name)
+					withAttrs := func(kv ...attribute.KeyValue) metric.ObserveOption {
+						return metric.WithAttributes(append(kv, poolName)...)
+					}
+
+					o.ObserveInt64(usage, int64(s.IdleConns()), withAttrs(semconv.StateIdle))
+					o.ObserveInt64(usage, int64(s.AcquiredConns()), withAttrs(semconv.StateUsed))
+					o.ObserveInt64(usage, int64(s.ConstructingConns()), withAttrs(semconv.StateKey.String("constructing")))
+					o.ObserveInt64(maxConns, int64(s.MaxConns()), withAttrs())
+					o.ObserveInt64(acquires, s.AcquireCount()-s.EmptyAcquireCount(), withAttrs(attrAcquire.String("idle")))
+					o.ObserveInt64(acquires, s.EmptyAcquireCount(), withAttrs(attrAcquire.String("empty")))
+					o.ObserveInt64(canceled, s.CanceledAcquireCount(), withAttrs())
+					o.ObserveFloat64(waitTime, s.AcquireDuration().Seconds(), withAttrs())
+					o.ObserveInt64(created, s.NewConnsCount(), withAttrs())
+					o.ObserveInt64(destroyed, s.MaxLifetimeDestroyCount(), withAttrs(attrReason.String("max_lifetime")))
+					o.ObserveInt64(destroyed, s.MaxIdleDestroyCount(), withAttrs(attrReason.String("max_idle_time")))
+				},
+			)
+			return nil
+		},
+		usage, maxConns, acquires, canceled, waitTime, created, destroyed,
+	)
+}
+
+// 连接池指标的属性
+const (
+	attrAcquire = attribute.Key("acquire")
+	attrReason  = attribute.Key("reason")
+)
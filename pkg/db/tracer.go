@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+const tracerName = "terraqt.io/colas/bedrock-go/pkg/db"
+
+// 非标准的 span 属性
+const (
+	attrRowsAffected    = attribute.Key("db.rows_affected")
+	attrBatchSize       = attribute.Key("db.batch.size")
+	attrTable           = attribute.Key("db.sql.table")
+	attrPreparedName    = attribute.Key("db.prepared_statement.name")
+	attrAlreadyPrepared = attribute.Key("db.prepared_statement.cached")
+)
+
+var (
+	tracerProviderMu sync.RWMutex
+	tracerProvider   trace.TracerProvider
+)
+
+// SetTracerProvider 设置创建 SQL span 使用的 TracerProvider，通常传入 otel.GetTracerProvider()。
+// 未设置时使用 ctx 中请求 span 所属的 TracerProvider，ctx 中没有 span 时不产生 span
+func SetTracerProvider(tp trace.TracerProvider) {
+	tracerProviderMu.Lock()
+	defer tracerProviderMu.Unlock()
+	tracerProvider = tp
+}
+
+func tracerFor(ctx context.Context) trace.Tracer {
+	tracerProviderMu.RLock()
+	tp := tracerProvider
+	tracerProviderMu.RUnlock()
+
+	if tp == nil {
+		tp = trace.SpanFromContext(ctx).TracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+// queryTrace 记录一次 SQL 执行的信息，在 start 与 end 之间通过 ctx 传递
+type queryTrace struct {
+	sql   string
+	args  []any
+	start time.Time
+}
+
+type queryTraceKey struct{}
+
+// traceLayer 是挂在 pgTracer 上的附加处理，如 SQL 日志。批量中的每条语句同样会回调
+type traceLayer interface {
+	queryStart(ctx context.Context, q *queryTrace)
+	queryEnd(ctx context.Context, q *queryTrace, tag pgconn.CommandTag, err error)
+}
+
+// pgTracer 为 query、batch、CopyFrom、prepare、connect 与 acquire 创建 span，
+// span 的父节点为调用方 ctx 中的 span（通常是请求 span）
+type pgTracer struct {
+	attrs  []attribute.KeyValue
+	layers []traceLayer
+}
+
+func newPGTracer(cfg DatabaseConfig) *pgTracer {
+	return &pgTracer{
+		attrs: []attribute.KeyValue{
+			semconv.DBSystemPostgreSQL,
+			semconv.DBName(cfg.Database),
+			semconv.ServerAddress(cfg.Host),
+			semconv.ServerPort(int(cfg.Port)),
+		},
+	}
+}
+
+func (t *pgTracer) start(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	ctx, _ = tracerFor(ctx).Start(
+		ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(t.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (t *pgTracer) end(ctx context.Context, err error, attrs ...attribute.KeyValue) {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attrs...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *pgTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := sqlOperation(data.SQL)
+	ctx = t.start(ctx, op, semconv.DBStatement(data.SQL), semconv.DBOperation(op))
+
+	q := &queryTrace{sql: data.SQL, args: data.Args, start: time.Now()}
+	for _, l := range t.layers {
+		l.queryStart(ctx, q)
+	}
+	return context.WithValue(ctx, queryTraceKey{}, q)
+}
+
+func (t *pgTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	if q, ok := ctx.Value(queryTraceKey{}).(*queryTrace); ok {
+		for _, l := range t.layers {
+			l.queryEnd(ctx, q, data.CommandTag, data.Err)
+		}
+	}
+	t.end(ctx, data.Err, attrRowsAffected.Int64(data.CommandTag.RowsAffected()))
+}
+
+func (t *pgTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	size := 0
+	if data.Batch != nil {
+		size = data.Batch.Len()
+	}
+	ctx = t.start(ctx, "BATCH", semconv.DBOperation("BATCH"), attrBatchSize.Int(size))
+	return context.WithValue(ctx, queryTraceKey{}, &queryTrace{start: time.Now()})
+}
+
+// TraceBatchQuery 将批量中的每条语句记录为 batch span 的事件。
+// 批量结果按顺序读取，每条语句的耗时从上一条语句结束（第一条为批量开始）计起
+func (t *pgTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	attrs := []attribute.KeyValue{
+		semconv.DBStatement(data.SQL),
+		semconv.DBOperation(sqlOperation(data.SQL)),
+		attrRowsAffected.Int64(data.CommandTag.RowsAffected()),
+	}
+	if data.Err != nil {
+		attrs = append(attrs, attribute.String("error", data.Err.Error()))
+	}
+	trace.SpanFromContext(ctx).AddEvent("query", trace.WithAttributes(attrs...))
+
+	if batch, ok := ctx.Value(queryTraceKey{}).(*queryTrace); ok {
+		q := &queryTrace{sql: data.SQL, args: data.Args, start: batch.start}
+		for _, l := range t.layers {
+			l.queryEnd(ctx, q, data.CommandTag, data.Err)
+		}
+		batch.start = time.Now()
+	}
+}
+
+func (t *pgTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, data.Err)
+}
+
+func (t *pgTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	table := data.TableName.Sanitize()
+	return t.start(ctx, "COPY "+table, semconv.DBOperation("COPY"), attrTable.String(table))
+}
+
+func (t *pgTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	t.end(ctx, data.Err, attrRowsAffected.Int64(data.CommandTag.RowsAffected()))
+}
+
+func (t *pgTracer) TracePrepareStart(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareStartData) context.Context {
+	return t.start(
+		ctx, "PREPARE",
+		semconv.DBStatement(data.SQL),
+		semconv.DBOperation("PREPARE"),
+		attrPreparedName.String(data.Name),
+	)
+}
+
+func (t *pgTracer) TracePrepareEnd(ctx context.Context, _ *pgx.Conn, data pgx.TracePrepareEndData) {
+	t.end(ctx, data.Err, attrAlreadyPrepared.Bool(data.AlreadyPrepared))
+}
+
+func (t *pgTracer) TraceConnectStart(ctx context.Context, _ pgx.TraceConnectStartData) context.Context {
+	return t.start(ctx, "CONNECT", semconv.DBOperation("CONNECT"))
+}
+
+func (t *pgTracer) TraceConnectEnd(ctx context.Context, data pgx.TraceConnectEndData) {
+	t.end(ctx, data.Err)
+}
+
+func (t *pgTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	return t.start(ctx, "ACQUIRE", semconv.DBOperation("ACQUIRE"))
+}
+
+func (t *pgTracer) TraceAcquireEnd(ctx context.Context, _ *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	t.end(ctx, data.Err)
+}
+
+// sqlOperation 取 SQL 的第一个关键字作为操作名，如 SELECT、INSERT
+func sqlOperation(sql string) string {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	end := strings.IndexFunc(
+		sql, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '(' || r == ';'
+		},
+	)
+	if end < 0 {
+		end = len(sql)
+	}
+	if end == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(sql[:end])
+}
+
+// logLayer 在 DebugSQL 开启时以 Debug 级别输出每条 SQL 及其耗时
+type logLayer struct {
+	logger.Logger
+}
+
+func (s *logLayer) queryStart(ctx context.Context, q *queryTrace) {
+	s.Debug(ctx, "SQL execution started", zap.String("sql", q.sql))
+}
+
+func (s *logLayer) queryEnd(ctx context.Context, q *queryTrace, _ pgconn.CommandTag, err error) {
+	if err != nil {
+		s.Error(ctx, "SQL execution failed", zap.String("sql", q.sql), zap.Error(err))
+		return
+	}
+	s.Debug(ctx, "SQL execution completed", zap.Duration("duration", time.Since(q.start)))
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+)
+
+// 序列化失败与死锁的重试参数
+const (
+	txMaxAttempts = 4
+	txBaseBackoff = 20 * time.Millisecond
+	txMaxBackoff  = 500 * time.Millisecond
+)
+
+// txKey 以连接池区分 ctx 中的事务，不同数据库的事务互不干扰
+type txKey struct {
+	pool *pgxpool.Pool
+}
+
+// txFromContext 返回 ctx 中属于 pool 的事务
+func txFromContext(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txKey{pool: pool}).(pgx.Tx)
+	return tx, ok
+}
+
+// WithTx 在 database 段的连接池上开启事务并执行 fn，事务保存在传给 fn 的 ctx 中，
+// 使用该 ctx 调用 InitializePGPool 返回的连接池的 Exec / Query / QueryRow / BeginTx 都会加入该事务。
+//
+//	err := db.WithTx(ctx, pgx.TxOptions{}, func(ctx context.Context) error {
+//		if _, err := pool.Exec(ctx, "UPDATE ..."); err != nil {
+//			return err
+//		}
+//		return repo.Save(ctx, order) // repo 内部使用同一个 pool 与 ctx
+//	})
+//
+// 嵌套、回滚与重试行为同 WithPoolTx，databases 段或 Registry 中的其他数据库请使用 WithPoolTx
+func WithTx(ctx context.Context, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	pool, err := InitializePGPool()
+	if err != nil {
+		return err
+	}
+	return WithPoolTx(ctx, pool, opts, fn)
+}
+
+// WithPoolTx 在 pool 上开启事务并执行 fn，使用传给 fn 的 ctx 调用 pool 的方法都会加入该事务。
+//
+// fn 返回错误或 panic 时回滚。ctx 中已有该 pool 的事务时嵌套调用转为 savepoint，失败只回滚到 savepoint，opts 被忽略。
+// 最外层事务遇到序列化失败（40001）或死锁（40P01）时按指数退避整体重试，fn 需保证可重复执行。
+// 提交与回滚失败返回 ErrDBTransaction
+func WithPoolTx(ctx context.Context, pool PGPool, opts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	adp := provideAdaptPool(pool)
+	if adp == nil {
+		return errs.WrapCodeError(errs.ErrNotImplemented, errors.New("adp is nil"))
+	}
+	stdPool := adp.getStdPool()
+
+	if tx, ok := txFromContext(ctx, stdPool); ok {
+		return runTx(ctx, stdPool, tx.Begin, fn)
+	}
+
+	var err error
+	for attempt := 0; attempt < txMaxAttempts; attempt++ {
+		if attempt > 0 {
+			if waitErr := txBackoff(ctx, attempt); waitErr != nil {
+				return errors.Join(err, waitErr)
+			}
+		}
+
+		err = runTx(
+			ctx, stdPool, func(ctx context.Context) (pgx.Tx, error) {
+				return pool.BeginTx(ctx, opts)
+			}, fn,
+		)
+		if !isRetryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// runTx 开启事务（或 savepoint）、执行 fn 并提交，失败时回滚
+func runTx(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	begin func(ctx context.Context) (pgx.Tx, error),
+	fn func(ctx context.Context) error,
+) (err error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return errs.WrapCodeError(errs.ErrDBTransaction, fmt.Errorf("failed to begin transaction: %w", err))
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{pool: pool}, tx)); err != nil {
+		// ctx 可能已取消，回滚仍需执行
+		if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return errors.Join(
+				err,
+				errs.WrapCodeError(errs.ErrDBTransaction, fmt.Errorf("failed to rollback transaction: %w", rbErr)),
+			)
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errs.WrapCodeError(errs.ErrDBTransaction, fmt.Errorf("failed to commit transaction: %w", err))
+	}
+	return nil
+}
+
+// isRetryable 判断错误是否为可整体重试的序列化失败或死锁
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == sqlStateSerial || pgErr.Code == sqlStateDeadlock
+}
+
+// txBackoff 等待指数退避时间（带抖动），ctx 结束时提前返回
+func txBackoff(ctx context.Context, attempt int) error {
+	backoff := min(txBaseBackoff<<(attempt-1), txMaxBackoff)
+	backoff = backoff/2 + rand.N(backoff/2+1)
+
+	timer := time.NewTimer(backoff)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"terraqt.io/colas/bedrock-go/pkg/errs"
+)
+
+// fakeTx 记录事务与 savepoint 的开启、提交与回滚，其余方法未实现
+type fakeTx struct {
+	pgx.Tx
+	name      string
+	events    *[]string
+	commitErr error
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	sp := &fakeTx{name: tx.name + ".sp", events: tx.events}
+	*tx.events = append(*tx.events, "begin "+sp.name)
+	return sp, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	*tx.events = append(*tx.events, "commit "+tx.name)
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	*tx.events = append(*tx.events, "rollback "+tx.name)
+	return nil
+}
+
+// fakeTxPool 以 begin 代替真实连接池开启事务
+type fakeTxPool struct {
+	*postgresPool
+	begin func() *fakeTx
+}
+
+func (p *fakeTxPool) BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error) {
+	return p.begin(), nil
+}
+
+func TestWithPoolTxSavepoint(t *testing.T) {
+	var events []string
+	pool := &fakeTxPool{
+		postgresPool: &postgresPool{},
+		begin: func() *fakeTx {
+			events = append(events, "begin tx")
+			return &fakeTx{name: "tx", events: &events}
+		},
+	}
+
+	errFailed := errors.New("failed")
+	err := WithPoolTx(
+		context.Background(), pool, pgx.TxOptions{}, func(ctx context.Context) error {
+			// 嵌套失败只回滚到 savepoint，外层事务继续
+			if err := WithPoolTx(
+				ctx, pool, pgx.TxOptions{}, func(context.Context) error {
+					return errFailed
+				},
+			); !errors.Is(err, errFailed) {
+				t.Errorf("nested WithPoolTx() error = %v, want %v", err, errFailed)
+			}
+			return WithPoolTx(
+				ctx, pool, pgx.TxOptions{}, func(context.Context) error {
+					return nil
+				},
+			)
+		},
+	)
+	if err != nil {
+		t.Fatalf("WithPoolTx() error = %v", err)
+	}
+
+	want := []string{
+		"begin tx",
+		"begin tx.sp", "rollback tx.sp",
+		"begin tx.sp", "commit tx.sp",
+		"commit tx",
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+}
+
+func TestWithPoolTxRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		commitErr error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: sqlStateSerial}, 2, false},
+		{"deadlock", &pgconn.PgError{Code: sqlStateDeadlock}, 2, false},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				var events []string
+				attempt := 0
+				pool := &fakeTxPool{
+					postgresPool: &postgresPool{},
+					begin: func() *fakeTx {
+						attempt++
+						tx := &fakeTx{name: "tx", events: &events}
+						// 只有第一次提交失败
+						if attempt == 1 {
+							tx.commitErr = tt.commitErr
+						}
+						return tx
+					},
+				}
+
+				calls := 0
+				err := WithPoolTx(
+					context.Background(), pool, pgx.TxOptions{}, func(context.Context) error {
+						calls++
+						return nil
+					},
+				)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("WithPoolTx() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				if calls != tt.wantCalls {
+					t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+				}
+			},
+		)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	commitErr := func(code string) error {
+		return errs.WrapCodeError(
+			errs.ErrDBTransaction,
+			fmt.Errorf("failed to commit transaction: %w", &pgconn.PgError{Code: code}),
+		)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure in commit", commitErr(sqlStateSerial), true},
+		{"deadlock in commit", commitErr(sqlStateDeadlock), true},
+		{"translated serialization failure", translatePGError(&pgconn.PgError{Code: sqlStateSerial}), true},
+		{"unique violation in commit", commitErr("23505"), false},
+		{"plain error", errors.New("failed"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
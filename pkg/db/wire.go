@@ -8,21 +8,30 @@ import (
 	"entgo.io/ent/dialect/sql"
 	"github.com/google/wire"
 	"github.com/jackc/pgx/v5"
-	"terraqt.io/colas/bedrock-go/pkg/config"
 	"terraqt.io/colas/bedrock-go/pkg/logger"
 )
 
 var PoolSet = wire.NewSet(
+	provideRegistry,
 	provideAdaptPool,
 	providePostgresPool,
 	provideDriver,
+	provideDB,
 	provideTransaction,
 )
 
+func InitializeRegistry() (*Registry, error) {
+	wire.Build(
+		logger.InitializeLogger,
+		provideRegistry,
+	)
+	return nil, nil
+}
+
 func InitializePGPool() (PGPool, error) {
 	wire.Build(
-		config.InitializeConfig,
-		wire.FieldsOf(new(config.Config), "Database"),
+		provideDatabaseConfig,
+		wire.Bind(new(ConfigGetter), new(DatabaseConfig)),
 		logger.InitializeLogger,
 		PoolSet,
 	)
@@ -32,8 +41,18 @@ func InitializePGPool() (PGPool, error) {
 
 func InitializeDriver() (*sql.Driver, error) {
 	wire.Build(
-		config.InitializeConfig,
-		wire.FieldsOf(new(config.Config), "Database"),
+		provideDatabaseConfig,
+		wire.Bind(new(ConfigGetter), new(DatabaseConfig)),
+		logger.InitializeLogger,
+		PoolSet,
+	)
+	return nil, nil
+}
+
+func InitializeDB() (DB, error) {
+	wire.Build(
+		provideDatabaseConfig,
+		wire.Bind(new(ConfigGetter), new(DatabaseConfig)),
 		logger.InitializeLogger,
 		PoolSet,
 	)
@@ -42,8 +61,8 @@ func InitializeDriver() (*sql.Driver, error) {
 
 func InitializeTx(tx context.Context, opts pgx.TxOptions) (pgx.Tx, error) {
 	wire.Build(
-		config.InitializeConfig,
-		wire.FieldsOf(new(config.Config), "Database"),
+		provideDatabaseConfig,
+		wire.Bind(new(ConfigGetter), new(DatabaseConfig)),
 		logger.InitializeLogger,
 		PoolSet,
 	)
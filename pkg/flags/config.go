@@ -0,0 +1,49 @@
+package flags
+
+import "terraqt.io/colas/bedrock-go/pkg/config"
+
+// Config 是 flags 段的配置，key 为 flag 名：
+//
+//	flags:
+//	  items:
+//	    new_checkout:
+//	      value: false
+//	      env:
+//	        dev: true
+//	      rollout:
+//	        percent: 20
+//	        by: tenant
+//	        value: true
+type Config struct {
+	// Salt 参与 rollout 分桶，修改后所有 flag 的灰度人群会重新打散
+	Salt  string                `mapstructure:"salt"`
+	Items map[string]FlagConfig `mapstructure:"items" validate:"dive"`
+}
+
+// FlagConfig 单个 flag 的取值，优先级：rollout 命中 > 当前 ENV 的值 > value > 代码中的默认值
+type FlagConfig struct {
+	Value   any            `mapstructure:"value"`
+	Env     map[string]any `mapstructure:"env"`
+	Rollout RolloutConfig  `mapstructure:"rollout"`
+}
+
+// RolloutConfig 按用户或租户的百分比灰度，命中的请求取 Value，未命中或 ctx 中缺少对应标识时按未配置处理
+type RolloutConfig struct {
+	Percent float64 `mapstructure:"percent" validate:"gte=0,lte=100"`
+	By      string  `mapstructure:"by" validate:"omitempty,oneof=user tenant"`
+	Value   any     `mapstructure:"value"`
+}
+
+var Section = config.Section[Config]("flags")
+
+// currentConfig 优先读取热更新后的配置，未加载时加载一次，加载失败时所有 flag 使用默认值
+func currentConfig() (Config, bool) {
+	if cfg, ok := config.Current[Config](); ok {
+		return cfg, true
+	}
+	cfg, err := Section.Load()
+	if err != nil {
+		return Config{}, false
+	}
+	return cfg, true
+}
@@ -0,0 +1,27 @@
+package flags
+
+import "context"
+
+type userKey struct{}
+
+type tenantKey struct{}
+
+// WithUser 在 ctx 中写入用户标识，供 rollout 按用户分桶，通常由鉴权中间件调用
+func WithUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// WithTenant 在 ctx 中写入租户标识，供 rollout 按租户分桶
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+func UserFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(userKey{}).(string)
+	return id, ok && id != ""
+}
+
+func TenantFrom(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(tenantKey{}).(string)
+	return id, ok && id != ""
+}
@@ -0,0 +1,314 @@
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"terraqt.io/colas/bedrock-go/pkg/config"
+	"terraqt.io/colas/bedrock-go/pkg/logger"
+)
+
+// Value 是 flag 支持的取值类型
+type Value interface {
+	~bool | ~string | ~int | ~int64 | ~float64
+}
+
+// 取值的来源，记录在评估日志与管理接口中
+const (
+	ReasonOverride = "override"
+	ReasonRollout  = "rollout"
+	ReasonEnv      = "env"
+	ReasonConfig   = "config"
+	ReasonDefault  = "default"
+)
+
+// Flag 是在代码中声明的类型化开关，通常声明为包级变量：
+//
+//	var NewCheckout = flags.Bool("new_checkout", false, "启用新结算流程")
+type Flag[T Value] struct {
+	key         string
+	def         T
+	description string
+
+	mu       sync.RWMutex
+	override *T
+}
+
+// entry 是类型擦除后的 flag，供管理接口统一处理
+type entry interface {
+	Key() string
+	Description() string
+	typeName() string
+	defaultValue() any
+	evaluate(ctx context.Context) (any, string)
+	overrideValue() (any, bool)
+	setOverride(raw any) error
+	clearOverride()
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]entry)
+
+	logMu sync.RWMutex
+	log   logger.Logger
+)
+
+// Define 声明一个 flag，同名 flag 只能声明一次，否则 panic。
+// viper 会将配置中的 map key 转为小写，且 . 会被拆分为多级 key，因此 key 须为不含 . 的小写字符串，否则 panic
+func Define[T Value](key string, def T, description string) *Flag[T] {
+	if key == "" || key != strings.ToLower(key) || strings.Contains(key, ".") {
+		panic(fmt.Sprintf("feature flag key '%s' must be lowercase and must not contain '.'", key))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[key]; ok {
+		panic(fmt.Sprintf("feature flag '%s' already defined", key))
+	}
+
+	f := &Flag[T]{key: key, def: def, description: description}
+	registry[key] = f
+	return f
+}
+
+func Bool(key string, def bool, description string) *Flag[bool] {
+	return Define(key, def, description)
+}
+
+func String(key string, def string, description string) *Flag[string] {
+	return Define(key, def, description)
+}
+
+func Int(key string, def int, description string) *Flag[int] {
+	return Define(key, def, description)
+}
+
+func Float64(key string, def float64, description string) *Flag[float64] {
+	return Define(key, def, description)
+}
+
+func Duration(key string, def time.Duration, description string) *Flag[time.Duration] {
+	return Define(key, def, description)
+}
+
+// SetLogger 设置评估日志，设置后每次 Get 以 Debug 级别记录 flag、取值与来源
+func SetLogger(l logger.Logger) {
+	logMu.Lock()
+	defer logMu.Unlock()
+	log = l
+}
+
+func (f *Flag[T]) Key() string {
+	return f.key
+}
+
+func (f *Flag[T]) Description() string {
+	return f.description
+}
+
+func (f *Flag[T]) Default() T {
+	return f.def
+}
+
+// Get 返回 flag 在 ctx 下的取值，优先级：运行时覆盖 > rollout 命中 > 当前 ENV 的值 > 配置值 > 默认值。
+// 配置值无法转换为 T 时按未配置处理
+func (f *Flag[T]) Get(ctx context.Context) T {
+	val, reason := f.get(ctx)
+
+	logMu.RLock()
+	l := log
+	logMu.RUnlock()
+	if l != nil {
+		fields := []zap.Field{
+			zap.String("flag", f.key),
+			zap.Any("value", val),
+			zap.String("reason", reason),
+		}
+		if id, ok := UserFrom(ctx); ok {
+			fields = append(fields, zap.String("user", id))
+		}
+		if id, ok := TenantFrom(ctx); ok {
+			fields = append(fields, zap.String("tenant", id))
+		}
+		l.Debug(ctx, "feature flag evaluated", fields...)
+	}
+
+	return val
+}
+
+func (f *Flag[T]) get(ctx context.Context) (T, string) {
+	f.mu.RLock()
+	override := f.override
+	f.mu.RUnlock()
+	if override != nil {
+		return *override, ReasonOverride
+	}
+
+	cfg, ok := currentConfig()
+	if !ok {
+		return f.def, ReasonDefault
+	}
+	fc, ok := cfg.Items[f.key]
+	if !ok {
+		return f.def, ReasonDefault
+	}
+
+	if fc.Rollout.Percent > 0 && fc.Rollout.Value != nil && inRollout(ctx, cfg.Salt, f.key, fc.Rollout) {
+		if val, err := convert[T](fc.Rollout.Value); err == nil {
+			return val, ReasonRollout
+		}
+	}
+	if raw, ok := fc.Env[config.CurrentEnv()]; ok && raw != nil {
+		if val, err := convert[T](raw); err == nil {
+			return val, ReasonEnv
+		}
+	}
+	if fc.Value != nil {
+		if val, err := convert[T](fc.Value); err == nil {
+			return val, ReasonConfig
+		}
+	}
+
+	return f.def, ReasonDefault
+}
+
+// Override 在运行时覆盖 flag 的取值，优先于所有配置，直到 ClearOverride
+func (f *Flag[T]) Override(val T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.override = &val
+}
+
+func (f *Flag[T]) ClearOverride() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.override = nil
+}
+
+func (f *Flag[T]) typeName() string {
+	return reflect.TypeOf(f.def).String()
+}
+
+func (f *Flag[T]) defaultValue() any {
+	return f.def
+}
+
+func (f *Flag[T]) evaluate(ctx context.Context) (any, string) {
+	return f.get(ctx)
+}
+
+func (f *Flag[T]) overrideValue() (any, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.override == nil {
+		return nil, false
+	}
+	return *f.override, true
+}
+
+func (f *Flag[T]) setOverride(raw any) error {
+	val, err := convert[T](raw)
+	if err != nil {
+		return err
+	}
+	f.Override(val)
+	return nil
+}
+
+func (f *Flag[T]) clearOverride() {
+	f.ClearOverride()
+}
+
+// inRollout 以 salt、flag 名与用户（或租户）标识哈希分桶，同一主体在同一 flag 上的结果稳定
+func inRollout(ctx context.Context, salt string, key string, r RolloutConfig) bool {
+	var (
+		id string
+		ok bool
+	)
+	if r.By == "tenant" {
+		id, ok = TenantFrom(ctx)
+	} else {
+		id, ok = UserFrom(ctx)
+	}
+	if !ok {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(salt + ":" + key + ":" + id))
+	return float64(h.Sum32()%10000) < r.Percent*100
+}
+
+// convert 将配置中的值转换为 T，环境变量等来源的值为字符串，需按 T 解析
+func convert[T Value](raw any) (T, error) {
+	var zeroT T
+	target := reflect.TypeOf(zeroT)
+	rv := reflect.ValueOf(raw)
+	if !rv.IsValid() {
+		return zeroT, fmt.Errorf("nil value for %s", target)
+	}
+
+	if s, ok := raw.(string); ok && target.Kind() != reflect.String {
+		parsed, err := parseString(target, s)
+		if err != nil {
+			return zeroT, err
+		}
+		rv = reflect.ValueOf(parsed)
+	}
+
+	if !compatible(rv.Kind(), target.Kind()) {
+		return zeroT, fmt.Errorf("cannot convert %T to %s", raw, target)
+	}
+	if isInt(target.Kind()) && isFloat(rv.Kind()) && rv.Float() != float64(int64(rv.Float())) {
+		return zeroT, fmt.Errorf("cannot convert %v to %s", raw, target)
+	}
+
+	return rv.Convert(target).Interface().(T), nil
+}
+
+func parseString(target reflect.Type, s string) (any, error) {
+	switch {
+	case target == reflect.TypeOf(time.Duration(0)):
+		return time.ParseDuration(s)
+	case target.Kind() == reflect.Bool:
+		return strconv.ParseBool(s)
+	case isInt(target.Kind()):
+		return strconv.ParseInt(s, 10, 64)
+	case isFloat(target.Kind()):
+		return strconv.ParseFloat(s, 64)
+	}
+	return nil, fmt.Errorf("cannot parse '%s' as %s", s, target)
+}
+
+func compatible(from, to reflect.Kind) bool {
+	switch {
+	case from == to:
+		return true
+	case (isInt(from) || isFloat(from)) && (isInt(to) || isFloat(to)):
+		return true
+	}
+	return false
+}
+
+func isInt(k reflect.Kind) bool {
+	return slices.Contains(
+		[]reflect.Kind{
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		}, k,
+	)
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
@@ -0,0 +1,29 @@
+package flags
+
+import "testing"
+
+func TestDefineInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "NewCheckout", "new.checkout"} {
+		t.Run(
+			key, func(t *testing.T) {
+				defer func() {
+					if recover() == nil {
+						t.Errorf("Define(%q) did not panic", key)
+					}
+				}()
+				Bool(key, false, "")
+			},
+		)
+	}
+}
+
+func TestDefineDuplicate(t *testing.T) {
+	Bool("define_duplicate_test", false, "")
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Define() of a duplicate key did not panic")
+		}
+	}()
+	Bool("define_duplicate_test", true, "")
+}
@@ -0,0 +1,146 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// State 描述 flag 的声明与当前取值，Value 为不带用户 / 租户信息时的取值
+type State struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Default     any    `json:"default"`
+	Value       any    `json:"value"`
+	Reason      string `json:"reason"`
+	Override    any    `json:"override,omitempty"`
+}
+
+// States 返回所有已声明 flag 的状态，按 key 排序
+func States() []State {
+	return states(nil)
+}
+
+func states(r *http.Request) []State {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	states := make([]State, 0, len(registry))
+	for _, e := range registry {
+		states = append(states, stateOf(e, r))
+	}
+	slices.SortFunc(
+		states, func(a, b State) int {
+			return strings.Compare(a.Key, b.Key)
+		},
+	)
+	return states
+}
+
+func stateOf(e entry, r *http.Request) State {
+	ctx := contextOf(r)
+	val, reason := e.evaluate(ctx)
+	s := State{
+		Key:         e.Key(),
+		Type:        e.typeName(),
+		Description: e.Description(),
+		Default:     e.defaultValue(),
+		Value:       val,
+		Reason:      reason,
+	}
+	if o, ok := e.overrideValue(); ok {
+		s.Override = o
+	}
+	return s
+}
+
+// contextOf 使用请求中的 user / tenant 参数构造 ctx，便于在管理接口中查看某个主体的取值
+func contextOf(r *http.Request) context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	ctx := context.Background()
+	if id := r.URL.Query().Get("user"); id != "" {
+		ctx = WithUser(ctx, id)
+	}
+	if id := r.URL.Query().Get("tenant"); id != "" {
+		ctx = WithTenant(ctx, id)
+	}
+	return ctx
+}
+
+func lookup(key string) (entry, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	e, ok := registry[key]
+	return e, ok
+}
+
+// overrideRequest 为 PUT 的请求体，value 可以是 JSON 值或可解析为 flag 类型的字符串
+type overrideRequest struct {
+	Value any `json:"value"`
+}
+
+// Handler 返回管理 flag 的 http.Handler，可挂载到管理端口：
+//
+//	GET    /            列出所有 flag，?user= / ?tenant= 可模拟请求方的取值
+//	GET    /{key}       查看单个 flag
+//	PUT    /{key}       运行时覆盖取值，请求体为 {"value": ...}
+//	DELETE /{key}       取消覆盖
+//
+// 覆盖只保存在当前进程内，重启后失效
+func Handler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			key := strings.Trim(r.URL.Path, "/")
+			if key == "" {
+				if r.Method != http.MethodGet {
+					writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+					return
+				}
+				writeJSON(w, http.StatusOK, states(r))
+				return
+			}
+
+			e, ok := lookup(key)
+			if !ok {
+				writeError(w, http.StatusNotFound, "unknown feature flag '"+key+"'")
+				return
+			}
+
+			switch r.Method {
+			case http.MethodGet:
+			case http.MethodPut, http.MethodPost:
+				var req overrideRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					writeError(w, http.StatusBadRequest, "decode request body error: "+err.Error())
+					return
+				}
+				if err := e.setOverride(req.Value); err != nil {
+					writeError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+			case http.MethodDelete:
+				e.clearOverride()
+			default:
+				writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+
+			writeJSON(w, http.StatusOK, stateOf(e, r))
+		},
+	)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
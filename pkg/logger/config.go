@@ -0,0 +1,19 @@
+package logger
+
+import "terraqt.io/colas/bedrock-go/pkg/config"
+
+// LoggerConfig 日志配置
+type LoggerConfig struct {
+	Level      string `mapstructure:"level" validate:"required,oneof=debug info warn error dpanic panic fatal" default:"info"`
+	Format     string `mapstructure:"format" validate:"required,oneof=json console" default:"json"`
+	AppName    string `mapstructure:"app_name"`
+	AppVersion string `mapstructure:"app_version"`
+
+	//OutputPath string `mapstructure:"output_path"`
+}
+
+var Section = config.Section[LoggerConfig]("logger")
+
+func provideLoggerConfig() (LoggerConfig, error) {
+	return Section.Load()
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"os"
 	"sync"
-	"terraqt.io/colas/bedrock-go/pkg/config"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
@@ -34,7 +33,7 @@ var (
 	zapLoggerOnce sync.Once
 )
 
-func provideZapLogger(lc config.LoggerConfig) (Logger, error) {
+func provideZapLogger(lc LoggerConfig) (Logger, error) {
 
 	level := lc.Level
 	format := lc.Format
@@ -5,18 +5,16 @@ package logger
 
 import (
 	"github.com/google/wire"
-	"terraqt.io/colas/bedrock-go/pkg/config"
 )
 
 var LoggerSet = wire.NewSet(
+	provideLoggerConfig,
 	provideZapLogger,
 )
 
 func InitializeLogger() (Logger, error) {
 	wire.Build(
 		LoggerSet,
-		config.InitializeConfig,
-		wire.FieldsOf(new(config.Config), "Logger"),
 	)
 	return nil, nil
 }